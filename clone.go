@@ -0,0 +1,73 @@
+package orgtree
+
+// Clone возвращает глубокую копию дерева. Значения копируются CopyValue
+func (n *Node) Clone() *Node {
+	return n.CloneWith(CopyValue)
+}
+
+// CloneWith возвращает копию дерева, значения которой получены функцией copier.
+// Структура копируется полностью, исходное дерево не изменяется.
+func (n *Node) CloneWith(copier func(interface{}) interface{}) *Node {
+	type pair struct {
+		src, dst *Node
+	}
+
+	root := NewNode(copier(n.Value))
+	stack := []pair{{n, root}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		p.dst.Children = make([]*Node, 0, len(p.src.Children))
+		for _, child := range p.src.Children {
+			childCopy := NewNode(copier(child.Value))
+			p.dst.AddChild(childCopy)
+			stack = append(stack, pair{child, childCopy})
+		}
+	}
+	return root
+}
+
+// CopyValue возвращает глубокую копию значения узла для встроенных моделей
+// (OrgNode, EmployeeNode, Position, NodeType), а также для map и срезов,
+// которые возвращает FromJSON. Остальные значения возвращаются как есть.
+func CopyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *OrgNode:
+		return v.Clone()
+	case OrgNode:
+		return *v.Clone()
+	case *EmployeeNode:
+		return v.Clone()
+	case EmployeeNode:
+		return *v.Clone()
+	case *Position:
+		return v.Clone()
+	case Position:
+		return v
+	case *NodeType:
+		return v.Clone()
+	case NodeType:
+		return v
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		clone := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			clone[key] = CopyValue(item)
+		}
+		return clone
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		clone := make([]interface{}, len(v))
+		for i, item := range v {
+			clone[i] = CopyValue(item)
+		}
+		return clone
+	default:
+		return value
+	}
+}
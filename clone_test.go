@@ -0,0 +1,100 @@
+package orgtree
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCloneDeepCopiesModels(t *testing.T) {
+	root := createTestTree()
+	clone := root.Clone()
+
+	original := NewPreOrderIterator(root)
+	copied := NewPreOrderIterator(clone)
+	for o, c := original.Next(), copied.Next(); o != nil || c != nil; o, c = original.Next(), copied.Next() {
+		if o == nil || c == nil {
+			t.Fatal("Clone has different number of nodes")
+		}
+		if o == c {
+			t.Fatal("Clone shares node pointers with original")
+		}
+		if len(o.Children) != len(c.Children) {
+			t.Fatalf("Children count mismatch for %v", o.Value)
+		}
+
+		oOrg, cOrg := o.Value.(*OrgNode), c.Value.(*OrgNode)
+		if oOrg == cOrg || oOrg.Type == cOrg.Type {
+			t.Error("Clone shares model pointers with original")
+		}
+		if oOrg.ID != cOrg.ID || oOrg.Name != cOrg.Name || oOrg.Type.SysName != cOrg.Type.SysName {
+			t.Errorf("Cloned value differs: %+v vs %+v", oOrg, cOrg)
+		}
+		for i := range oOrg.Positions {
+			if oOrg.Positions[i] == cOrg.Positions[i] || oOrg.Positions[i].ID != cOrg.Positions[i].ID {
+				t.Error("Positions are not deep-copied")
+			}
+		}
+	}
+
+	for _, child := range clone.Children {
+		if child.Parent() != clone {
+			t.Error("Cloned child has wrong parent")
+		}
+	}
+
+	// Изменение копии не затрагивает оригинал
+	clone.Value.(*OrgNode).Name = "Changed"
+	clone.Value.(*OrgNode).Positions[0].Name = "Changed"
+	clone.Children[0].AddChild(NewNode("extra"))
+
+	rootOrg := root.Value.(*OrgNode)
+	if rootOrg.Name != "Engineering" || rootOrg.Positions[0].Name != "CEO" {
+		t.Error("Modifying clone changed the original values")
+	}
+	if len(root.Children[0].Children) != 1 {
+		t.Error("Modifying clone changed the original structure")
+	}
+}
+
+func TestCloneWith(t *testing.T) {
+	root := createTestTree()
+
+	shallow := root.CloneWith(func(v interface{}) interface{} { return v })
+	if shallow.Value != root.Value {
+		t.Error("Identity copier must keep original values")
+	}
+	if shallow == root || shallow.Children[0] == root.Children[0] {
+		t.Error("CloneWith must copy structure")
+	}
+
+	names := root.CloneWith(func(v interface{}) interface{} {
+		return v.(*OrgNode).Name
+	})
+	if names.Value != "Engineering" || names.Children[0].Children[0].Value != "John Doe" {
+		t.Errorf("Unexpected values after mapping copier: %v", names.Value)
+	}
+}
+
+func TestCopyValue(t *testing.T) {
+	employee := &EmployeeNode{ID: uuid.New(), Name: "Иван", Type: &NodeType{SysName: "employee"}}
+	copied := CopyValue(employee).(*EmployeeNode)
+	if copied == employee || copied.Type == employee.Type || copied.Name != employee.Name {
+		t.Error("EmployeeNode is not deep-copied")
+	}
+
+	jsonValue := map[string]interface{}{"name": "x", "tags": []interface{}{"a"}}
+	copiedMap := CopyValue(jsonValue).(map[string]interface{})
+	copiedMap["tags"].([]interface{})[0] = "b"
+	if jsonValue["tags"].([]interface{})[0] != "a" {
+		t.Error("JSON values are not deep-copied")
+	}
+
+	if CopyValue("text") != "text" || CopyValue(nil) != nil {
+		t.Error("Unexpected copy of simple value")
+	}
+	var nilOrg *OrgNode
+	if CopyValue(nilOrg).(*OrgNode) != nil {
+		t.Error("Expected nil copy for nil *OrgNode")
+	}
+}
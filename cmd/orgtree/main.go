@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/arsants/orgtree"
+	"github.com/arsants/orgtree/generic"
 	"github.com/google/uuid"
 )
 
@@ -482,8 +483,9 @@ func main() {
 		}
 	}
 
-	// Построение дерева
+	// Построение дерева. Связи команд добавлялись из map, поэтому упорядочиваем детей по имени
 	orgTree := builder.BuildTree()
+	orgTree.SortRecursive(orgtree.ByName)
 
 	// Выводим результат
 	orgTreeJSON, _ := orgTree.ToJSON()
@@ -522,7 +524,7 @@ func main() {
 	// Поиск всех команд разработки
 	fmt.Println("\nПоиск всех команд разработки:")
 	devPattern := regexp.MustCompile("разработки")
-	devSubtree, searchErr := orgTree.FilterSubtreeByRegex(devPattern.String())
+	devSubtree, searchErr := orgTree.FilterSubtreeByRegexField(devPattern.String(), orgtree.FieldName)
 	if searchErr != nil {
 		log.Fatalf("Ошибка при поиске команд разработки: %v", searchErr)
 	} else if devSubtree != nil {
@@ -536,15 +538,10 @@ func main() {
 
 	// Поиск всех команд, содержащих определенное слово
 	fmt.Println("\nПоиск всех команд, содержащих 'команда':")
-	teamPredicate := func(value interface{}) bool {
-		if orgNode, ok := value.(*orgtree.OrgNode); ok {
-			return strings.Contains(orgNode.Name, "команда")
-		}
-		return false
-	}
-
-	teamSubtree := orgTree.FilterSubtree(teamPredicate)
-	if teamSubtree != nil {
+	teamSubtree, searchErr := orgTree.FilterSubtreeByRegexFieldWith("команда", orgtree.MatchOptions{IgnoreCase: true, Normalize: true}, orgtree.FieldName)
+	if searchErr != nil {
+		log.Fatalf("Ошибка при поиске команд: %v", searchErr)
+	} else if teamSubtree != nil {
 		teamJSON, err := teamSubtree.ToJSON()
 		if err != nil {
 			log.Fatalf("Ошибка при сериализации найденных команд: %v", err)
@@ -552,4 +549,46 @@ func main() {
 		fmt.Println("Найденные команды:")
 		printJSON(teamJSON)
 	}
+
+	// Численность и должности по подразделениям
+	fmt.Println("\n=== Численность по подразделениям ===")
+	orgTree.PrintTreeWithRollups(orgtree.Rollups(orgTree, nil))
+
+	// Режимы фильтрации: найденные узлы отмечены звездочкой
+	fmt.Println("\n=== Режимы фильтрации ===")
+	isTesting := func(value interface{}) bool {
+		orgNode, ok := value.(*orgtree.OrgNode)
+		return ok && orgNode.SysName == "qa_team"
+	}
+	fmt.Println("Команда тестирования целиком:")
+	orgTree.FilterSubtreeMode(isTesting, orgtree.FilterKeepSubtrees).PrintTree()
+	fmt.Println("Без отдела кадров:")
+	orgTree.FilterSubtreeMode(func(value interface{}) bool {
+		orgNode, ok := value.(*orgtree.OrgNode)
+		return ok && orgNode.SysName == "hr_department"
+	}, orgtree.FilterPrune).PrintTree()
+
+	// Запрос по пути и атрибутам
+	fmt.Println("\n=== Запрос к дереву ===")
+	query := "/main_office/it_department//*[positions.sysname=qa_lead]"
+	found, err := orgTree.Select(query)
+	if err != nil {
+		log.Fatalf("Ошибка разбора запроса: %v", err)
+	}
+	fmt.Printf("%s:\n", query)
+	for _, node := range found {
+		fmt.Printf("  %s\n", node.Value.(*orgtree.OrgNode).Name)
+	}
+
+	// Демонстрация типизированного дерева
+	fmt.Println("\n=== Типизированное дерево ===")
+	typedTree, err := generic.FromNode[*orgtree.OrgNode](orgTree)
+	if err != nil {
+		log.Fatalf("Ошибка при преобразовании дерева: %v", err)
+	}
+	typedTree.WalkTree(func(node *generic.Node[*orgtree.OrgNode], depth int) {
+		if node.Value != nil && len(node.Value.Positions) > 0 {
+			fmt.Printf("%s- %s (%s)\n", strings.Repeat("  ", depth), node.Value.Name, node.Value.Positions[0].Name)
+		}
+	})
 }
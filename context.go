@@ -0,0 +1,81 @@
+package orgtree
+
+import "context"
+
+// ctxDone возвращает ошибку контекста; для контекстов без отмены проверка ничего не стоит
+func ctxDone(ctx context.Context) error {
+	if ctx.Done() == nil {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// CtxIterator оборачивает итератор и прекращает обход при отмене контекста
+type CtxIterator struct {
+	ctx context.Context
+	it  Iterator
+	err error
+}
+
+// NewCtxIterator возвращает итератор, который перестает выдавать узлы после отмены ctx
+func NewCtxIterator(ctx context.Context, it Iterator) *CtxIterator {
+	return &CtxIterator{ctx: ctx, it: it}
+}
+
+// NewPreOrderIteratorCtx возвращает прямой итератор, учитывающий отмену ctx
+func NewPreOrderIteratorCtx(ctx context.Context, root *Node) *CtxIterator {
+	return NewCtxIterator(ctx, NewPreOrderIterator(root))
+}
+
+// NewPostOrderIteratorCtx возвращает обратный итератор, учитывающий отмену ctx
+func NewPostOrderIteratorCtx(ctx context.Context, root *Node) *CtxIterator {
+	return NewCtxIterator(ctx, NewPostOrderIterator(root))
+}
+
+// NewBFSIteratorCtx возвращает итератор в ширину, учитывающий отмену ctx
+func NewBFSIteratorCtx(ctx context.Context, root *Node) *CtxIterator {
+	return NewCtxIterator(ctx, NewBFSIterator(root))
+}
+
+// Next возвращает следующий узел или nil, если обход закончен или контекст отменен
+func (it *CtxIterator) Next() *Node {
+	if it.err != nil {
+		return nil
+	}
+	if it.err = ctxDone(it.ctx); it.err != nil {
+		return nil
+	}
+	return it.it.Next()
+}
+
+// Err возвращает ошибку контекста, если обход был прерван
+func (it *CtxIterator) Err() error {
+	return it.err
+}
+
+// WalkTreeCtx обходит дерево как WalkTree и возвращает ctx.Err() при отмене контекста
+func (n *Node) WalkTreeCtx(ctx context.Context, callback func(*Node, int)) error {
+	return n.walkTree(ctx, callback)
+}
+
+// FilterSubtreeCtx фильтрует дерево как FilterSubtree и возвращает ctx.Err() при отмене контекста
+func (n *Node) FilterSubtreeCtx(ctx context.Context, predicate func(interface{}) bool) (*Node, error) {
+	return n.filterSubtree(ctx, predicate)
+}
+
+// FindCtx ищет узел как Find и возвращает ctx.Err() при отмене контекста
+func (n *Node) FindCtx(ctx context.Context, value interface{}) (*Node, error) {
+	it := NewPreOrderIteratorCtx(ctx, n)
+	for node := it.Next(); node != nil; node = it.Next() {
+		if valuesEqual(node.Value, value) {
+			return node, nil
+		}
+	}
+	return nil, it.Err()
+}
+
+// HashCtx вычисляет хеш дерева как Hash и возвращает ctx.Err() при отмене контекста.
+// Уже вычисленные хеши поддеревьев остаются в кеше.
+func (n *Node) HashCtx(ctx context.Context) ([]byte, error) {
+	return subtreeHash(ctx, n)
+}
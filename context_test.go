@@ -0,0 +1,192 @@
+package orgtree
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// createLargeTree строит дерево с заданным ветвлением и глубиной
+func createLargeTree(fanout, depth int) (*Node, int) {
+	root := NewNode(0)
+	count := 1
+	level := []*Node{root}
+	for d := 0; d < depth; d++ {
+		next := []*Node{}
+		for _, node := range level {
+			for i := 0; i < fanout; i++ {
+				child := NewNode(count)
+				count++
+				node.AddChild(child)
+				next = append(next, child)
+			}
+		}
+		level = next
+	}
+	return root, count
+}
+
+// countdownContext отменяется после заданного числа проверок Err
+type countdownContext struct {
+	context.Context
+	done      chan struct{}
+	remaining int
+}
+
+func newCountdownContext(checks int) *countdownContext {
+	return &countdownContext{Context: context.Background(), done: make(chan struct{}), remaining: checks}
+}
+
+func (c *countdownContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *countdownContext) Err() error {
+	c.remaining--
+	if c.remaining < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestWalkTreeCtxCancellation(t *testing.T) {
+	root, total := createLargeTree(10, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	visited := 0
+	err := root.WalkTreeCtx(ctx, func(n *Node, depth int) {
+		visited++
+		if visited == 100 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if visited != 100 {
+		t.Errorf("Expected walk to stop after 100 of %d nodes, visited %d", total, visited)
+	}
+
+	// Без отмены обходятся все узлы
+	visited = 0
+	if err := root.WalkTreeCtx(context.Background(), func(*Node, int) { visited++ }); err != nil || visited != total {
+		t.Errorf("Expected %d nodes without error, got %d (%v)", total, visited, err)
+	}
+}
+
+func TestFilterSubtreeCtxCancellation(t *testing.T) {
+	root, total := createLargeTree(10, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	filtered, err := root.FilterSubtreeCtx(ctx, func(v interface{}) bool {
+		calls++
+		if calls == 1000 {
+			cancel()
+		}
+		return v.(int)%7 == 0
+	})
+	if !errors.Is(err, context.Canceled) || filtered != nil {
+		t.Fatalf("Expected context.Canceled and nil tree, got %v", err)
+	}
+	if calls != 1000 {
+		t.Errorf("Expected filter to stop after 1000 of %d predicate calls, got %d", total, calls)
+	}
+
+	predicate := func(v interface{}) bool { return v.(int)%7 == 0 }
+	filtered, err = root.FilterSubtreeCtx(context.Background(), predicate)
+	if err != nil {
+		t.Fatalf("FilterSubtreeCtx failed: %v", err)
+	}
+	if filtered.HashString() != root.FilterSubtree(predicate).HashString() {
+		t.Error("FilterSubtreeCtx result differs from FilterSubtree")
+	}
+}
+
+func TestFindCtxDeadline(t *testing.T) {
+	root, _ := createLargeTree(10, 4)
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if node, err := root.FindCtx(ctx, 5); !errors.Is(err, context.DeadlineExceeded) || node != nil {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+
+	node, err := root.FindCtx(context.Background(), 5)
+	if err != nil || node == nil || node.Value != 5 {
+		t.Errorf("FindCtx failed: %v", err)
+	}
+	if node, err := root.FindCtx(context.Background(), -1); err != nil || node != nil {
+		t.Errorf("Expected nil without error for missing value, got %v (%v)", node, err)
+	}
+}
+
+func TestHashCtxCancellation(t *testing.T) {
+	root, total := createLargeTree(10, 4)
+
+	ctx := newCountdownContext(500)
+	if _, err := root.HashCtx(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	hashed := 0
+	for node := range root.PreOrder() {
+		if node.hash.Load() != nil {
+			hashed++
+		}
+	}
+	if hashed == 0 || hashed >= total {
+		t.Errorf("Expected hashing to stop part-way, hashed %d of %d nodes", hashed, total)
+	}
+
+	// Продолжение после отмены использует уже вычисленные хеши
+	sum, err := root.HashCtx(context.Background())
+	if err != nil {
+		t.Fatalf("HashCtx failed: %v", err)
+	}
+	fresh := root.Clone()
+	if string(sum) != string(fresh.Hash()) {
+		t.Error("Hash after resumed computation differs from fresh hash")
+	}
+}
+
+func TestCtxIterators(t *testing.T) {
+	root, total := createLargeTree(10, 4)
+
+	for name, newIt := range map[string]func(context.Context, *Node) *CtxIterator{
+		"PreOrder":  NewPreOrderIteratorCtx,
+		"PostOrder": NewPostOrderIteratorCtx,
+		"BFS":       NewBFSIteratorCtx,
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		it := newIt(ctx, root)
+		count := 0
+		for node := it.Next(); node != nil; node = it.Next() {
+			count++
+			if count == 10 {
+				cancel()
+			}
+		}
+		if count != 10 || !errors.Is(it.Err(), context.Canceled) {
+			t.Errorf("%s: expected stop after 10 nodes with Canceled, got %d (%v)", name, count, it.Err())
+		}
+		if it.Next() != nil {
+			t.Errorf("%s: expected nil after cancellation", name)
+		}
+		cancel()
+
+		it = newIt(context.Background(), root)
+		count = 0
+		for node := it.Next(); node != nil; node = it.Next() {
+			count++
+		}
+		if count != total || it.Err() != nil {
+			t.Errorf("%s: expected %d nodes, got %d (%v)", name, total, count, it.Err())
+		}
+	}
+}
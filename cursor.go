@@ -0,0 +1,232 @@
+package orgtree
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidCursor возвращается для поврежденного или неподдерживаемого курсора
+	ErrInvalidCursor = errors.New("orgtree: некорректный курсор")
+	// ErrCursorMismatch возвращается, если курсор получен для дерева с другим корнем
+	ErrCursorMismatch = errors.New("orgtree: курсор относится к другому дереву")
+)
+
+// cursorVersion — версия формата токена курсора
+const cursorVersion = 1
+
+// cursorStep описывает один шаг пути от корня: ID узла и его номер среди соседей
+type cursorStep struct {
+	ID    uuid.UUID `json:"id"`
+	Index int       `json:"i"`
+}
+
+// cursorToken — содержимое токена курсора до кодирования в base64
+type cursorToken struct {
+	Version int          `json:"v"`
+	Path    []cursorStep `json:"p"`
+}
+
+// CursorIterator обходит дерево в прямом порядке и позволяет сохранить текущую позицию
+// в виде непрозрачного токена, чтобы продолжить обход позже, в том числе на измененном дереве.
+//
+// Позиция задается путем от корня по ID узлов, а не указателями. При возобновлении узел
+// ищется по ID среди детей родителя, поэтому перестановка соседей и изменения в других
+// ветках не мешают продолжить обход. Если узел курсора удален или перенесен в другую ветку,
+// обход продолжается с места, где узел находился: с ребенка его ближайшего сохранившегося
+// предка, стоявшего на прежней позиции. В этом случае Displaced возвращает true.
+// Узлы без ID (не OrgNode и не EmployeeNode) сопоставляются только по номеру среди соседей.
+type CursorIterator struct {
+	inner     *PreOrderIterator
+	displaced bool
+	start     string
+	moved     bool
+}
+
+// NewCursorIterator создает итератор, начинающий обход с корня
+func NewCursorIterator(root *Node) *CursorIterator {
+	return &CursorIterator{inner: NewPreOrderIterator(root)}
+}
+
+// ResumeFromCursor создает итератор, продолжающий обход после узла, сохраненного в cursor.
+// Пустой cursor означает начало обхода.
+func ResumeFromCursor(root *Node, cursor string) (*CursorIterator, error) {
+	if cursor == "" {
+		return NewCursorIterator(root), nil
+	}
+
+	token, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return nil, ErrNilNode
+	}
+	if id, ok := valueID(root.Value); ok && id != token.Path[0].ID {
+		return nil, ErrCursorMismatch
+	}
+
+	// Спускаемся по пути, пока находятся узлы курсора
+	path := []*Node{root}
+	indexes := []int{0}
+	for _, step := range token.Path[1:] {
+		parent := path[len(path)-1]
+		index := findCursorChild(parent, step)
+		if index < 0 {
+			break
+		}
+		path = append(path, parent.Children[index])
+		indexes = append(indexes, index)
+	}
+
+	it := &CursorIterator{inner: &PreOrderIterator{}, start: cursor}
+	// Для каждого уровня кладем в стек соседей справа от узла пути
+	for depth := 1; depth < len(path); depth++ {
+		it.inner.pushSiblings(path[depth-1], indexes[depth]+1, depth)
+	}
+
+	if len(path) == len(token.Path) {
+		// Узел найден: следующий Next начнет с его детей
+		it.inner.path = path
+		it.inner.last = path[len(path)-1]
+		return it, nil
+	}
+
+	// Узел удален: продолжаем с прежней позиции у ближайшего сохранившегося предка
+	it.displaced = true
+	depth := len(path)
+	it.inner.pushSiblings(path[depth-1], token.Path[depth].Index, depth)
+	it.inner.path = path
+	return it, nil
+}
+
+// findCursorChild возвращает номер ребенка, соответствующего шагу курсора, или -1
+func findCursorChild(parent *Node, step cursorStep) int {
+	if step.ID == uuid.Nil {
+		if step.Index < len(parent.Children) {
+			return step.Index
+		}
+		return -1
+	}
+
+	// Сначала проверяем прежнюю позицию, затем ищем среди всех детей
+	if step.Index < len(parent.Children) {
+		if id, ok := valueID(parent.Children[step.Index].Value); ok && id == step.ID {
+			return step.Index
+		}
+	}
+	for i, child := range parent.Children {
+		if id, ok := valueID(child.Value); ok && id == step.ID {
+			return i
+		}
+	}
+	return -1
+}
+
+// pushSiblings кладет в стек детей parent начиная с from в обратном порядке
+func (it *PreOrderIterator) pushSiblings(parent *Node, from, depth int) {
+	for i := len(parent.Children) - 1; i >= from; i-- {
+		it.stack = append(it.stack, depthEntry{parent.Children[i], depth})
+	}
+}
+
+func (it *CursorIterator) Next() *Node {
+	node := it.inner.Next()
+	if node != nil {
+		it.moved = true
+	}
+	return node
+}
+
+// Depth возвращает глубину последнего возвращенного узла
+func (it *CursorIterator) Depth() int {
+	return it.inner.Depth()
+}
+
+// Displaced сообщает, что узел курсора не найден и обход продолжен с его прежней позиции
+func (it *CursorIterator) Displaced() bool {
+	return it.displaced
+}
+
+// Cursor возвращает токен позиции после последнего возвращенного узла.
+// Пока Next не вернул ни одного узла, возвращается исходный курсор (пустой для начала обхода).
+// После завершения обхода токен указывает на последний узел, и возобновленный обход сразу заканчивается.
+func (it *CursorIterator) Cursor() string {
+	if !it.moved {
+		return it.start
+	}
+
+	path := it.inner.path
+	token := cursorToken{Version: cursorVersion, Path: make([]cursorStep, len(path))}
+	for depth, node := range path {
+		step := cursorStep{}
+		if id, ok := valueID(node.Value); ok {
+			step.ID = id
+		}
+		if depth > 0 {
+			step.Index = path[depth-1].indexOf(node)
+		}
+		token.Path[depth] = step
+	}
+	return encodeCursor(token)
+}
+
+// Page возвращает не более limit узлов в прямом порядке, начиная с позиции cursor,
+// и токен для следующей страницы. Пустой токен означает, что обход завершен.
+func Page(root *Node, cursor string, limit int) ([]*Node, string, error) {
+	if limit <= 0 {
+		return nil, cursor, nil
+	}
+	it, err := ResumeFromCursor(root, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nodes []*Node
+	for len(nodes) < limit {
+		node := it.Next()
+		if node == nil {
+			return nodes, "", nil
+		}
+		nodes = append(nodes, node)
+	}
+
+	// Проверяем, остались ли узлы, не сдвигая итератор
+	next := it.Cursor()
+	if last := it.inner.last; len(last.Children) == 0 && len(it.inner.stack) == 0 {
+		next = ""
+	}
+	return nodes, next, nil
+}
+
+func encodeCursor(token cursorToken) string {
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string) (cursorToken, error) {
+	var token cursorToken
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return token, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return token, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if token.Version != cursorVersion {
+		return token, fmt.Errorf("%w: версия %d", ErrInvalidCursor, token.Version)
+	}
+	if len(token.Path) == 0 {
+		return token, fmt.Errorf("%w: пустой путь", ErrInvalidCursor)
+	}
+	for _, step := range token.Path {
+		if step.Index < 0 {
+			return token, fmt.Errorf("%w: индекс %d", ErrInvalidCursor, step.Index)
+		}
+	}
+	return token, nil
+}
@@ -0,0 +1,121 @@
+package orgtree
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPageThroughTree(t *testing.T) {
+	root, _ := createIndexedTree()
+
+	var all []*Node
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("Pagination did not terminate")
+		}
+		nodes, next, err := Page(root, cursor, 2)
+		if err != nil {
+			t.Fatalf("Page failed: %v", err)
+		}
+		all = append(all, nodes...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	assertNames(t, orgNames(all), "", "IT отдел", "Команда разработки", "Иван", "Команда тестирования", "HR отдел")
+}
+
+func TestCursorSurvivesChanges(t *testing.T) {
+	root, ids := createIndexedTree()
+
+	it := NewCursorIterator(root)
+	it.Next() // корень
+	it.Next() // IT отдел
+	it.Next() // Команда разработки
+	cursor := it.Cursor()
+
+	// Перестановка соседей и изменения в других ветках не влияют на позицию
+	itNode := root.Children[0]
+	if err := itNode.SwapChildren(0, 1); err != nil {
+		t.Fatal(err)
+	}
+	root.AddChild(NewNode(&OrgNode{ID: uuid.New(), Name: "Финансы"}))
+
+	resumed, err := ResumeFromCursor(root, cursor)
+	if err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if resumed.Displaced() {
+		t.Error("Expected cursor node to be found")
+	}
+	var rest []*Node
+	for node := resumed.Next(); node != nil; node = resumed.Next() {
+		rest = append(rest, node)
+	}
+	assertNames(t, orgNames(rest), "Иван", "HR отдел", "Финансы")
+
+	// После удаления узла курсора обход продолжается с его прежней позиции
+	root, ids = createIndexedTree()
+	it = NewCursorIterator(root)
+	for node := it.Next(); node != nil; node = it.Next() {
+		if id, _ := valueID(node.Value); id == ids["dev_team"] {
+			break
+		}
+	}
+	cursor = it.Cursor()
+	root.Children[0].Children[0].Detach()
+
+	resumed, err = ResumeFromCursor(root, cursor)
+	if err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if !resumed.Displaced() {
+		t.Error("Expected cursor to be displaced")
+	}
+	if resumed.Cursor() != cursor {
+		t.Error("Expected original cursor before the first Next")
+	}
+	rest = nil
+	for node := resumed.Next(); node != nil; node = resumed.Next() {
+		rest = append(rest, node)
+	}
+	assertNames(t, orgNames(rest), "Команда тестирования", "HR отдел")
+}
+
+func TestCursorAtEnd(t *testing.T) {
+	root, _ := createIndexedTree()
+
+	it := NewCursorIterator(root)
+	for it.Next() != nil {
+	}
+	resumed, err := ResumeFromCursor(root, it.Cursor())
+	if err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if node := resumed.Next(); node != nil {
+		t.Errorf("Expected exhausted iterator, got %v", node.Value)
+	}
+}
+
+func TestCursorErrors(t *testing.T) {
+	root, _ := createIndexedTree()
+
+	for _, cursor := range []string{"not base64!", "e30", "eyJ2Ijo5OSwicCI6W3t9XX0"} {
+		if _, err := ResumeFromCursor(root, cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Cursor %q: expected ErrInvalidCursor, got %v", cursor, err)
+		}
+	}
+
+	// Курсор, полученный на поддереве, не подходит для другого корня
+	it := NewCursorIterator(root.Children[0])
+	it.Next()
+	it.Next()
+	if _, err := ResumeFromCursor(root.Children[1], it.Cursor()); !errors.Is(err, ErrCursorMismatch) {
+		t.Errorf("Expected ErrCursorMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package orgtree
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// deepChainLength — глубина вырожденной цепочки для проверки отсутствия рекурсии
+const deepChainLength = 1_000_000
+
+// createChain строит цепочку из length узлов со значениями 0..length-1
+func createChain(length int) (root, leaf *Node) {
+	root = NewNode(0)
+	leaf = root
+	for i := 1; i < length; i++ {
+		next := NewNode(i)
+		leaf.AddChild(next)
+		leaf = next
+	}
+	return root, leaf
+}
+
+func TestDeepChain(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping deep chain test in short mode")
+	}
+
+	root, leaf := createChain(deepChainLength)
+
+	t.Run("GetPath", func(t *testing.T) {
+		if depth, ok := leaf.GetDepth(root); !ok || depth != deepChainLength-1 {
+			t.Errorf("Expected depth %d, got %d (%v)", deepChainLength-1, depth, ok)
+		}
+
+		// Обертка без ссылок на родителей вынуждает искать путь обходом
+		wrapper := &Node{Value: "wrapper", Children: []*Node{root}}
+		path := leaf.GetPath(wrapper)
+		if len(path) != deepChainLength+1 || path[0] != wrapper || path[len(path)-1] != leaf {
+			t.Errorf("Unexpected path length %d", len(path))
+		}
+	})
+
+	t.Run("WalkTree", func(t *testing.T) {
+		count, maxDepth := 0, 0
+		root.WalkTree(func(n *Node, depth int) {
+			count++
+			maxDepth = max(maxDepth, depth)
+		})
+		if count != deepChainLength || maxDepth != deepChainLength-1 {
+			t.Errorf("Expected %d nodes down to depth %d, got %d and %d", deepChainLength, deepChainLength-1, count, maxDepth)
+		}
+	})
+
+	t.Run("FilterSubtree", func(t *testing.T) {
+		filtered := root.FilterSubtree(func(v interface{}) bool { return v == deepChainLength-1 })
+		count := 0
+		for range filtered.PreOrder() {
+			count++
+		}
+		if count != deepChainLength {
+			t.Errorf("Expected the whole chain to be kept, got %d nodes", count)
+		}
+	})
+
+	t.Run("PostOrder", func(t *testing.T) {
+		it := NewPostOrderIterator(root)
+		if first := it.Next(); first != leaf {
+			t.Errorf("Expected leaf first, got %v", first.Value)
+		}
+		count := 1
+		for it.Next() != nil {
+			count++
+		}
+		if count != deepChainLength {
+			t.Errorf("Expected %d nodes, got %d", deepChainLength, count)
+		}
+	})
+
+	t.Run("Fold", func(t *testing.T) {
+		height := FoldUp(root, func(n *Node, children []int) int {
+			if len(children) == 0 {
+				return 0
+			}
+			return children[0] + 1
+		})
+		if height != deepChainLength-1 {
+			t.Errorf("Expected height %d, got %d", deepChainLength-1, height)
+		}
+
+		depths := FoldDown(root, -1, func(n *Node, parent int) int { return parent + 1 })
+		if depths[leaf] != deepChainLength-1 {
+			t.Errorf("Expected leaf depth %d, got %d", deepChainLength-1, depths[leaf])
+		}
+
+		mapped := MapTree(root, func(n *Node) interface{} { return n.Value.(int) * 2 })
+		if mapped.Hash() == nil || len(mapped.Children) != 1 {
+			t.Error("Expected mapped chain")
+		}
+	})
+
+	t.Run("Hash", func(t *testing.T) {
+		other, _ := createChain(deepChainLength)
+		if root.HashString() != other.HashString() {
+			t.Error("Expected identical chains to have identical hashes")
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := root.WriteJSON(&buf); err != nil {
+			t.Fatalf("WriteJSON failed: %v", err)
+		}
+		restored, err := ReadJSON(&buf)
+		if err != nil {
+			t.Fatalf("ReadJSON failed: %v", err)
+		}
+
+		node, depth := restored, 0
+		for len(node.Children) == 1 {
+			node = node.Children[0]
+			depth++
+		}
+		if depth != deepChainLength-1 || node.Value != float64(deepChainLength-1) {
+			t.Errorf("Expected leaf %d at depth %d, got %v at depth %d", deepChainLength-1, deepChainLength-1, node.Value, depth)
+		}
+	})
+}
+
+func TestDeepChainPrintTree(t *testing.T) {
+	// Отступы растут с глубиной, поэтому объем вывода квадратичен и цепочка короче
+	const length = 5000
+	root, _ := createChain(length)
+
+	var buf bytes.Buffer
+	if err := root.WriteTree(&buf); err != nil {
+		t.Fatalf("WriteTree failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != length {
+		t.Fatalf("Expected %d lines, got %d", length, len(lines))
+	}
+	expected := strings.Repeat("    ", length-1) + "└── 4999"
+	if lines[length-1] != expected {
+		t.Errorf("Unexpected last line prefix length %d", len(lines[length-1]))
+	}
+}
+
+func TestToJSONIndentedLayout(t *testing.T) {
+	root := createStringTree()
+	data, err := root.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{
+  "children": [
+    {
+      "children": [
+        {
+          "children": [],
+          "value": "grandchild1"
+        }
+      ],
+      "value": "child1"
+    },
+    {
+      "children": [
+        {
+          "children": [],
+          "value": "grandchild2"
+        }
+      ],
+      "value": "child2"
+    }
+  ],
+  "value": "root"
+}`
+	if string(data) != expected {
+		t.Errorf("Unexpected JSON layout:\n%s", data)
+	}
+
+	// Ключи в любом порядке и неизвестные поля допускаются
+	restored, err := FromJSON([]byte(`{"value": "root", "children": [{"value": "a", "extra": {"x": [1, 2]}}]}`))
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	if restored.Value != "root" || len(restored.Children) != 1 || restored.Children[0].Value != "a" {
+		t.Errorf("Unexpected tree: %v with %d children", restored.Value, len(restored.Children))
+	}
+
+	for _, input := range []string{
+		`{"value": 1} trailing`,
+		`{"value": }`,
+		`{"children": [{"value": 1},]}`,
+		`{"value": "unterminated`,
+		`["not", "a", "node"]`,
+		`{"value": "root", "children": [{"value": "a"}, 42]}`,
+		`{"children": [{"value": "a"}], "children": [{"value": "b"}]}`,
+	} {
+		if _, err := FromJSON([]byte(input)); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+}
+
+func TestToJSONSharedNodes(t *testing.T) {
+	shared := NewNode("shared")
+	root := NewNode("root")
+	a, b := NewNode("a"), NewNode("b")
+	root.AddChild(a)
+	root.AddChild(b)
+	a.Children = append(a.Children, shared)
+	b.Children = append(b.Children, shared)
+
+	data, err := root.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), `"shared"`); n != 1 {
+		t.Errorf("Expected the shared node to be written once, got %d times:\n%s", n, data)
+	}
+}
@@ -0,0 +1,281 @@
+package orgtree
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ChangeKind описывает вид изменения между двумя версиями дерева
+type ChangeKind string
+
+const (
+	ChangeAdded     ChangeKind = "added"
+	ChangeRemoved   ChangeKind = "removed"
+	ChangeMoved     ChangeKind = "moved"
+	ChangeRenamed   ChangeKind = "renamed"
+	ChangeRetyped   ChangeKind = "retyped"
+	ChangePositions ChangeKind = "positions_changed"
+)
+
+// Change описывает одно изменение узла. Заполняются только поля, относящиеся к виду изменения
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	ID   uuid.UUID  `json:"id"`
+	Name string     `json:"name"`
+
+	OldParent     *uuid.UUID `json:"old_parent,omitempty"`
+	OldParentName string     `json:"old_parent_name,omitempty"`
+	NewParent     *uuid.UUID `json:"new_parent,omitempty"`
+	NewParentName string     `json:"new_parent_name,omitempty"`
+
+	OldName    string `json:"old_name,omitempty"`
+	NewName    string `json:"new_name,omitempty"`
+	OldSysName string `json:"old_sysname,omitempty"`
+	NewSysName string `json:"new_sysname,omitempty"`
+
+	OldType *NodeType `json:"old_type,omitempty"`
+	NewType *NodeType `json:"new_type,omitempty"`
+
+	AddedPositions   []*Position `json:"added_positions,omitempty"`
+	RemovedPositions []*Position `json:"removed_positions,omitempty"`
+}
+
+// TreeDiff содержит список изменений между двумя версиями дерева
+type TreeDiff struct {
+	Changes []Change `json:"changes"`
+}
+
+// diffEntry описывает положение узла с ID в одной из версий дерева
+type diffEntry struct {
+	node   *Node
+	parent *Node
+}
+
+// Diff сравнивает две версии дерева по ID узлов OrgNode и EmployeeNode.
+// Узлы без ID (например, корень-заглушка TreeBuilder) не сравниваются.
+// Изменения перечисляются в прямом порядке нового дерева, удаленные узлы — в конце
+// в прямом порядке старого дерева.
+func Diff(oldRoot, newRoot *Node) *TreeDiff {
+	oldEntries, oldOrder := collectDiffEntries(oldRoot)
+	newEntries, newOrder := collectDiffEntries(newRoot)
+
+	diff := &TreeDiff{Changes: []Change{}}
+	for _, id := range newOrder {
+		newEntry := newEntries[id]
+		oldEntry, existed := oldEntries[id]
+		if !existed {
+			change := newChange(ChangeAdded, id, newEntry.node)
+			change.NewParent, change.NewParentName = parentRef(newEntry.parent)
+			diff.Changes = append(diff.Changes, change)
+			continue
+		}
+		diff.Changes = append(diff.Changes, compareEntries(id, oldEntry, newEntry)...)
+	}
+
+	for _, id := range oldOrder {
+		if _, exists := newEntries[id]; !exists {
+			oldEntry := oldEntries[id]
+			change := newChange(ChangeRemoved, id, oldEntry.node)
+			change.OldParent, change.OldParentName = parentRef(oldEntry.parent)
+			diff.Changes = append(diff.Changes, change)
+		}
+	}
+
+	return diff
+}
+
+// Empty сообщает, что версии не различаются
+func (d *TreeDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// ByKind возвращает изменения данного вида
+func (d *TreeDiff) ByKind(kind ChangeKind) []Change {
+	changes := []Change{}
+	for _, change := range d.Changes {
+		if change.Kind == kind {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// ToJSON сериализует список изменений в JSON
+func (d *TreeDiff) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// String возвращает текстовое представление изменений, по одному на строку
+func (d *TreeDiff) String() string {
+	var b strings.Builder
+	for _, change := range d.Changes {
+		b.WriteString(change.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// String возвращает текстовое представление изменения
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %q добавлен в %q", c.Name, c.NewParentName)
+	case ChangeRemoved:
+		return fmt.Sprintf("- %q удален из %q", c.Name, c.OldParentName)
+	case ChangeMoved:
+		return fmt.Sprintf("~ %q перемещен: %q → %q", c.Name, c.OldParentName, c.NewParentName)
+	case ChangeRenamed:
+		return fmt.Sprintf("~ %q переименован: %q (%s) → %q (%s)", c.Name, c.OldName, c.OldSysName, c.NewName, c.NewSysName)
+	case ChangeRetyped:
+		return fmt.Sprintf("~ %q сменил тип: %s → %s", c.Name, typeLabel(c.OldType), typeLabel(c.NewType))
+	case ChangePositions:
+		parts := []string{}
+		for _, p := range c.AddedPositions {
+			parts = append(parts, "+"+p.SysName)
+		}
+		for _, p := range c.RemovedPositions {
+			parts = append(parts, "-"+p.SysName)
+		}
+		return fmt.Sprintf("~ %q изменены должности: %s", c.Name, strings.Join(parts, " "))
+	default:
+		return fmt.Sprintf("? %q %s", c.Name, c.Kind)
+	}
+}
+
+// collectDiffEntries собирает узлы с ID и их родителей в прямом порядке
+func collectDiffEntries(root *Node) (map[uuid.UUID]diffEntry, []uuid.UUID) {
+	entries := make(map[uuid.UUID]diffEntry)
+	order := []uuid.UUID{}
+	if root == nil {
+		return entries, order
+	}
+
+	stack := []diffEntry{{node: root}}
+	for len(stack) > 0 {
+		entry := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if id, ok := valueID(entry.node.Value); ok {
+			if _, seen := entries[id]; !seen {
+				entries[id] = entry
+				order = append(order, id)
+			}
+		}
+		for i := len(entry.node.Children) - 1; i >= 0; i-- {
+			stack = append(stack, diffEntry{node: entry.node.Children[i], parent: entry.node})
+		}
+	}
+	return entries, order
+}
+
+// compareEntries сравнивает две версии одного узла
+func compareEntries(id uuid.UUID, oldEntry, newEntry diffEntry) []Change {
+	changes := []Change{}
+	oldValue, newValue := oldEntry.node.Value, newEntry.node.Value
+
+	oldParent, oldParentName := parentRef(oldEntry.parent)
+	newParent, newParentName := parentRef(newEntry.parent)
+	if !sameParent(oldParent, newParent) {
+		change := newChange(ChangeMoved, id, newEntry.node)
+		change.OldParent, change.OldParentName = oldParent, oldParentName
+		change.NewParent, change.NewParentName = newParent, newParentName
+		changes = append(changes, change)
+	}
+
+	if valueName(oldValue) != valueName(newValue) || valueSysName(oldValue) != valueSysName(newValue) {
+		change := newChange(ChangeRenamed, id, newEntry.node)
+		change.OldName, change.NewName = valueName(oldValue), valueName(newValue)
+		change.OldSysName, change.NewSysName = valueSysName(oldValue), valueSysName(newValue)
+		changes = append(changes, change)
+	}
+
+	oldType, newType := valueType(oldValue), valueType(newValue)
+	if !sameType(oldType, newType) {
+		change := newChange(ChangeRetyped, id, newEntry.node)
+		change.OldType, change.NewType = oldType, newType
+		changes = append(changes, change)
+	}
+
+	added, removed := diffPositions(valuePositions(oldValue), valuePositions(newValue))
+	if len(added) > 0 || len(removed) > 0 {
+		change := newChange(ChangePositions, id, newEntry.node)
+		change.AddedPositions, change.RemovedPositions = added, removed
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func newChange(kind ChangeKind, id uuid.UUID, node *Node) Change {
+	return Change{Kind: kind, ID: id, Name: valueName(node.Value)}
+}
+
+// parentRef возвращает ID и имя родителя; для корня и узлов без ID — nil
+func parentRef(parent *Node) (*uuid.UUID, string) {
+	if parent == nil {
+		return nil, ""
+	}
+	if id, ok := valueID(parent.Value); ok {
+		return &id, valueName(parent.Value)
+	}
+	return nil, ""
+}
+
+func sameParent(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// sameType сравнивает типы по системному имени
+func sameType(a, b *NodeType) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.SysName == b.SysName
+}
+
+func typeLabel(t *NodeType) string {
+	if t == nil {
+		return "<нет>"
+	}
+	return t.SysName
+}
+
+// valuePositions возвращает должности узла оргструктуры
+func valuePositions(value interface{}) []*Position {
+	if v, ok := value.(*OrgNode); ok && v != nil {
+		return v.Positions
+	}
+	return nil
+}
+
+// diffPositions сравнивает наборы должностей по ID, пропуская nil
+func diffPositions(oldPositions, newPositions []*Position) (added, removed []*Position) {
+	oldIDs := make(map[uuid.UUID]bool, len(oldPositions))
+	for _, p := range oldPositions {
+		if p != nil {
+			oldIDs[p.ID] = true
+		}
+	}
+	newIDs := make(map[uuid.UUID]bool, len(newPositions))
+	for _, p := range newPositions {
+		if p == nil {
+			continue
+		}
+		newIDs[p.ID] = true
+		if !oldIDs[p.ID] {
+			added = append(added, p)
+		}
+	}
+	for _, p := range oldPositions {
+		if p != nil && !newIDs[p.ID] {
+			removed = append(removed, p)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,142 @@
+package orgtree
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDiffIdenticalTrees(t *testing.T) {
+	root, _ := createIndexedTree()
+	if diff := Diff(root, root.Clone()); !diff.Empty() {
+		t.Errorf("Expected no changes, got:\n%s", diff)
+	}
+}
+
+func TestDiffDetectsChanges(t *testing.T) {
+	oldRoot, ids := createIndexedTree()
+	newRoot := oldRoot.Clone()
+
+	idx := NewTreeIndex(newRoot)
+	defer idx.Close()
+
+	// Перемещение команды разработки в HR
+	if err := idx.ByID(ids["dev_team"]).MoveTo(idx.ByID(ids["hr_department"]), -1); err != nil {
+		t.Fatalf("MoveTo failed: %v", err)
+	}
+	// Переименование и смена типа
+	qa := idx.ByID(ids["qa_team"]).Value.(*OrgNode)
+	qa.Name = "Команда качества"
+	qa.Type = &NodeType{ID: uuid.New(), Name: "Отдел", SysName: "department"}
+	// Новые должности
+	hr := idx.ByID(ids["hr_department"]).Value.(*OrgNode)
+	hr.Positions = []*Position{{ID: uuid.New(), Name: "HR менеджер", SysName: "hr_manager"}}
+	// Удаление сотрудника и добавление нового отдела
+	ivan := idx.ByID(ids["ivan"])
+	ivan.Detach()
+	newID := uuid.New()
+	newRoot.AddChild(NewNode(&OrgNode{ID: newID, Name: "Финансы", SysName: "finance"}))
+
+	diff := Diff(oldRoot, newRoot)
+
+	moved := diff.ByKind(ChangeMoved)
+	if len(moved) != 1 || moved[0].ID != ids["dev_team"] {
+		t.Fatalf("Expected dev_team to be moved, got %+v", moved)
+	}
+	if *moved[0].OldParent != ids["it_department"] || *moved[0].NewParent != ids["hr_department"] {
+		t.Errorf("Wrong parents for moved node: %+v", moved[0])
+	}
+
+	renamed := diff.ByKind(ChangeRenamed)
+	if len(renamed) != 1 || renamed[0].OldName != "Команда тестирования" || renamed[0].NewName != "Команда качества" {
+		t.Errorf("Unexpected renames: %+v", renamed)
+	}
+
+	retyped := diff.ByKind(ChangeRetyped)
+	if len(retyped) != 1 || retyped[0].OldType.SysName != "team" || retyped[0].NewType.SysName != "department" {
+		t.Errorf("Unexpected retypes: %+v", retyped)
+	}
+
+	positions := diff.ByKind(ChangePositions)
+	if len(positions) != 1 || len(positions[0].AddedPositions) != 1 || positions[0].AddedPositions[0].SysName != "hr_manager" {
+		t.Errorf("Unexpected position changes: %+v", positions)
+	}
+
+	removed := diff.ByKind(ChangeRemoved)
+	if len(removed) != 1 || removed[0].ID != ids["ivan"] || removed[0].OldParentName != "Команда разработки" {
+		t.Errorf("Unexpected removals: %+v", removed)
+	}
+
+	added := diff.ByKind(ChangeAdded)
+	if len(added) != 1 || added[0].ID != newID || added[0].NewParent != nil {
+		t.Errorf("Unexpected additions: %+v", added)
+	}
+
+	if len(diff.Changes) != 6 {
+		t.Errorf("Expected 6 changes, got %d:\n%s", len(diff.Changes), diff)
+	}
+}
+
+func TestDiffRendering(t *testing.T) {
+	oldRoot, ids := createIndexedTree()
+	newRoot := oldRoot.Clone()
+	idx := NewTreeIndex(newRoot)
+	defer idx.Close()
+
+	idx.ByID(ids["dev_team"]).MoveTo(idx.ByID(ids["hr_department"]), 0)
+	diff := Diff(oldRoot, newRoot)
+
+	text := diff.String()
+	if !strings.Contains(text, `"Команда разработки" перемещен: "IT отдел" → "HR отдел"`) {
+		t.Errorf("Unexpected text rendering:\n%s", text)
+	}
+
+	data, err := diff.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	var decoded TreeDiff
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode diff JSON: %v", err)
+	}
+	if len(decoded.Changes) != 1 || decoded.Changes[0].Kind != ChangeMoved || *decoded.Changes[0].NewParent != ids["hr_department"] {
+		t.Errorf("Unexpected decoded diff: %s", data)
+	}
+	if strings.Contains(string(data), "old_name") {
+		t.Error("Irrelevant fields must be omitted from JSON")
+	}
+}
+
+func TestDiffWithNilTrees(t *testing.T) {
+	root, _ := createIndexedTree()
+
+	if added := Diff(nil, root).ByKind(ChangeAdded); len(added) != 5 {
+		t.Errorf("Expected 5 added nodes, got %d", len(added))
+	}
+	if removed := Diff(root, nil).ByKind(ChangeRemoved); len(removed) != 5 {
+		t.Errorf("Expected 5 removed nodes, got %d", len(removed))
+	}
+}
+
+func TestDiffSkipsNilPositions(t *testing.T) {
+	oldRoot, ids := createIndexedTree()
+	newRoot := oldRoot.Clone()
+
+	idx := NewTreeIndex(newRoot)
+	defer idx.Close()
+
+	it := idx.ByID(ids["it_department"])
+	it.Value = &OrgNode{ID: ids["it_department"], Name: "IT отдел", SysName: "it_department", Positions: []*Position{nil}}
+	if changes := Diff(oldRoot, newRoot).ByKind(ChangePositions); len(changes) != 0 {
+		t.Errorf("Expected nil positions to be ignored, got %v", changes)
+	}
+
+	cto := &Position{ID: uuid.New(), Name: "CTO", SysName: "cto"}
+	it.Value = &OrgNode{ID: ids["it_department"], Name: "IT отдел", SysName: "it_department", Positions: []*Position{nil, cto}}
+	changes := Diff(oldRoot, newRoot).ByKind(ChangePositions)
+	if len(changes) != 1 || len(changes[0].AddedPositions) != 1 || changes[0].AddedPositions[0] != cto {
+		t.Errorf("Expected only CTO to be added, got %v", changes)
+	}
+}
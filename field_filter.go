@@ -0,0 +1,217 @@
+package orgtree
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Встроенные поля FilterSubtreeByRegexField вместе с FieldName и FieldSysName
+const (
+	// FieldTypeName — имя типа узла
+	FieldTypeName = "type_name"
+	// FieldPositions — имена должностей OrgNode
+	FieldPositions = "positions"
+)
+
+// FieldExtractor извлекает строковые значения поля из значения узла.
+// Пустой результат означает, что у значения нет такого поля.
+type FieldExtractor interface {
+	Extract(value interface{}) []string
+}
+
+// FieldExtractorFunc позволяет использовать функцию как FieldExtractor
+type FieldExtractorFunc func(value interface{}) []string
+
+func (f FieldExtractorFunc) Extract(value interface{}) []string {
+	return f(value)
+}
+
+// FieldStringer реализуется пользовательскими значениями узлов, чтобы отдавать поля
+// без регистрации извлекателя: поле с любым именем запрашивается у значения при сравнении.
+// Для OrgNode и EmployeeNode используются встроенные извлекатели.
+type FieldStringer interface {
+	FieldString(field string) (string, bool)
+}
+
+var (
+	fieldExtractorsMu sync.RWMutex
+	fieldExtractors   = map[string]FieldExtractor{
+		FieldName: FieldExtractorFunc(func(v interface{}) []string {
+			return nonEmpty(valueName(v))
+		}),
+		FieldSysName: FieldExtractorFunc(func(v interface{}) []string {
+			return nonEmpty(valueSysName(v))
+		}),
+		FieldTypeName: FieldExtractorFunc(func(v interface{}) []string {
+			if t := valueType(v); t != nil {
+				return nonEmpty(t.Name)
+			}
+			return nil
+		}),
+		FieldPositions: FieldExtractorFunc(func(v interface{}) []string {
+			var names []string
+			for _, p := range valuePositions(v) {
+				if p != nil && p.Name != "" {
+					names = append(names, p.Name)
+				}
+			}
+			return names
+		}),
+	}
+)
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+// RegisterField регистрирует извлекатель поля для FilterSubtreeByRegexField.
+// Повторная регистрация заменяет извлекатель, в том числе встроенный.
+func RegisterField(name string, extractor FieldExtractor) {
+	fieldExtractorsMu.Lock()
+	defer fieldExtractorsMu.Unlock()
+	fieldExtractors[name] = extractor
+}
+
+// RegisteredFields возвращает имена зарегистрированных полей по алфавиту
+func RegisteredFields() []string {
+	fieldExtractorsMu.RLock()
+	defer fieldExtractorsMu.RUnlock()
+	names := make([]string, 0, len(fieldExtractors))
+	for name := range fieldExtractors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MatchOptions задает режим сравнения для FieldMatcher
+type MatchOptions struct {
+	// IgnoreCase — сравнение без учета регистра, в том числе для кириллицы
+	IgnoreCase bool
+	// Normalize — привести шаблон и значения к NFC (разложенные «й» и «ё» совпадают
+	// с составными) и заменить в них «ё» на «е»; пробелы в значениях схлопываются.
+	// Пробелы в шаблоне не меняются, чтобы не менять смысл регулярного выражения
+	Normalize bool
+}
+
+// FieldMatcher проверяет значения узлов регулярным выражением по набору полей
+type FieldMatcher struct {
+	re     *regexp.Regexp
+	opts   MatchOptions
+	fields []string
+	// extractors зафиксированы при создании, чтобы не брать блокировку на каждом узле;
+	// для незарегистрированных полей — nil
+	extractors []FieldExtractor
+}
+
+// NewFieldMatcher компилирует шаблон для полей fields; без полей используются все
+// зарегистрированные. Незарегистрированные поля запрашиваются у значений через FieldStringer.
+func NewFieldMatcher(pattern string, opts MatchOptions, fields ...string) (*FieldMatcher, error) {
+	if len(fields) == 0 {
+		fields = RegisteredFields()
+	}
+
+	fieldExtractorsMu.RLock()
+	extractors := make([]FieldExtractor, len(fields))
+	for i, field := range fields {
+		extractors[i] = fieldExtractors[field]
+	}
+	fieldExtractorsMu.RUnlock()
+
+	if opts.Normalize {
+		pattern = foldYo.Replace(norm.NFC.String(pattern))
+	}
+	if opts.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &FieldMatcher{re: re, opts: opts, fields: fields, extractors: extractors}, nil
+}
+
+// MatchValue проверяет, что хотя бы одно значение выбранных полей соответствует шаблону
+func (m *FieldMatcher) MatchValue(value interface{}) bool {
+	stringer, isStringer := value.(FieldStringer)
+	for i, extractor := range m.extractors {
+		var values []string
+		if extractor != nil {
+			values = extractor.Extract(value)
+		}
+		if isStringer {
+			if s, ok := stringer.FieldString(m.fields[i]); ok {
+				values = append(values, s)
+			}
+		}
+		for _, s := range values {
+			if m.matchString(s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Match проверяет значение узла; позволяет передать FieldMatcher в FilterSubtreeBy
+func (m *FieldMatcher) Match(n *Node) bool {
+	return m.MatchValue(n.Value)
+}
+
+// FilterSubtreeByRegexField фильтрует дерево как FilterSubtree, сопоставляя шаблон с полями
+// значений узлов: FieldName, FieldSysName, FieldPositions, FieldTypeName, зарегистрированными
+// через RegisterField или отдаваемыми FieldStringer. Без полей проверяются все зарегистрированные.
+func (n *Node) FilterSubtreeByRegexField(pattern string, fields ...string) (*Node, error) {
+	return n.FilterSubtreeByRegexFieldWith(pattern, MatchOptions{}, fields...)
+}
+
+// FilterSubtreeByRegexFieldWith работает как FilterSubtreeByRegexField с параметрами сравнения
+func (n *Node) FilterSubtreeByRegexFieldWith(pattern string, opts MatchOptions, fields ...string) (*Node, error) {
+	m, err := NewFieldMatcher(pattern, opts, fields...)
+	if err != nil {
+		return nil, err
+	}
+	return n.filterNodes(context.Background(), m.Match)
+}
+
+// matchString проверяет строку, при необходимости нормализуя ее так же, как шаблон
+func (m *FieldMatcher) matchString(s string) bool {
+	if m.opts.Normalize {
+		s = foldYo.Replace(normalizeText(s))
+	}
+	return m.re.MatchString(s)
+}
+
+// foldYo заменяет «ё» на «е» в строке, уже приведенной к NFC
+var foldYo = strings.NewReplacer("ё", "е", "Ё", "Е")
+
+// normalizeText приводит значение к NFC и схлопывает последовательности
+// пробельных символов, включая неразрывный пробел, в один пробел
+func normalizeText(s string) string {
+	s = norm.NFC.String(s)
+
+	var b strings.Builder
+	b.Grow(len(s))
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
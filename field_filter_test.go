@@ -0,0 +1,182 @@
+package orgtree
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// matchedNames возвращает имена узлов дерева, для которых срабатывает FieldMatcher
+func matchedNames(t *testing.T, root *Node, pattern string, opts MatchOptions, fields ...string) []string {
+	t.Helper()
+	m, err := NewFieldMatcher(pattern, opts, fields...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nodes []*Node
+	for node := range root.PreOrder() {
+		if m.Match(node) {
+			nodes = append(nodes, node)
+		}
+	}
+	return orgNames(nodes)
+}
+
+func TestFilterSubtreeByRegexField(t *testing.T) {
+	root := createQueryTree()
+
+	filtered, err := root.FilterSubtreeByRegexField("разработки", FieldName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filtered == nil {
+		t.Fatal("Expected a match on OrgNode names")
+	}
+	office := filtered.Children[0]
+	assertNames(t, orgNames(office.Children), "IT отдел")
+	assertNames(t, orgNames(office.Children[0].Children), "Команда разработки")
+	// Потомки найденного узла, не подходящие под шаблон, отсекаются
+	if len(office.Children[0].Children[0].Children) != 0 {
+		t.Error("Expected non-matching descendants to be dropped")
+	}
+
+	if filtered, _ := root.FilterSubtreeByRegex("разработки"); filtered != nil {
+		t.Error("FilterSubtreeByRegex still matches only string values")
+	}
+}
+
+func TestFieldMatcherFields(t *testing.T) {
+	root := createQueryTree()
+
+	tests := []struct {
+		name     string
+		pattern  string
+		fields   []string
+		expected []string
+	}{
+		{"sysname", "^qa_", []string{FieldSysName}, []string{"Команда тестирования"}},
+		{"type name", "^Team$", []string{FieldTypeName}, []string{"Команда разработки", "Mobile команда", "Команда тестирования", "Рекрутинг"}},
+		{"position names", "QA Lead", []string{FieldPositions}, []string{"Mobile команда", "Команда тестирования"}},
+		{"employee name", "^Иван$", []string{FieldName}, []string{"Иван"}},
+		{"several fields", "^(Рекрутинг|dev_team)$", []string{FieldName, FieldSysName}, []string{"Команда разработки", "Рекрутинг"}},
+		{"all fields", "Employee|hr_department", nil, []string{"Иван", "HR отдел"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertNames(t, matchedNames(t, root, tt.pattern, MatchOptions{}, tt.fields...), tt.expected...)
+		})
+	}
+}
+
+func TestFieldMatcherOptions(t *testing.T) {
+	root := NewNode(nil)
+	for _, name := range []string{
+		"Служба Поддержки",
+		"Отдел  расче\u0308тов",
+		"Отдел\u00a0расчетов",
+		"Линейный персонал",
+		"Линеи\u0306ныи\u0306 персонал",
+	} {
+		root.AddChild(NewNode(&OrgNode{ID: uuid.New(), Name: name}))
+	}
+
+	assertNames(t, matchedNames(t, root, "поддержки", MatchOptions{}, FieldName))
+	assertNames(t, matchedNames(t, root, "поддержки", MatchOptions{IgnoreCase: true}, FieldName), "Служба Поддержки")
+
+	// Без нормализации разложенная «ё», двойной и неразрывный пробелы различаются
+	assertNames(t, matchedNames(t, root, "Отдел расчетов", MatchOptions{}, FieldName))
+	assertNames(t, matchedNames(t, root, "^Отдел расчетов$", MatchOptions{Normalize: true}, FieldName),
+		"Отдел  расче\u0308тов", "Отдел\u00a0расчетов")
+	// «ё» в шаблоне нормализуется так же, как в значениях
+	assertNames(t, matchedNames(t, root, "^Отдел расчётов$", MatchOptions{Normalize: true}, FieldName),
+		"Отдел  расче\u0308тов", "Отдел\u00a0расчетов")
+	assertNames(t, matchedNames(t, root, "^отдел расче\u0308тов$", MatchOptions{IgnoreCase: true, Normalize: true}, FieldName),
+		"Отдел  расче\u0308тов", "Отдел\u00a0расчетов")
+	// Пробелы в шаблоне сохраняют смысл регулярного выражения
+	assertNames(t, matchedNames(t, root, " Отдел", MatchOptions{Normalize: true}, FieldName))
+
+	// Разложенная «й» совпадает с составной и в значении, и в шаблоне
+	assertNames(t, matchedNames(t, root, "^Линейный", MatchOptions{}, FieldName), "Линейный персонал")
+	assertNames(t, matchedNames(t, root, "^линеи\u0306ный", MatchOptions{IgnoreCase: true, Normalize: true}, FieldName),
+		"Линейный персонал", "Линеи\u0306ныи\u0306 персонал")
+}
+
+// budgetValue — пользовательское значение узла с собственными полями
+type budgetValue struct {
+	Name   string
+	Owner  string
+	Amount string
+}
+
+func (v budgetValue) FieldString(field string) (string, bool) {
+	switch field {
+	case FieldName:
+		return v.Name, true
+	case "owner":
+		return v.Owner, true
+	case "budget":
+		return v.Amount, true
+	}
+	return "", false
+}
+
+func TestFieldMatcherCustomFields(t *testing.T) {
+	RegisterField("owner", FieldExtractorFunc(func(v interface{}) []string {
+		if org, ok := v.(*OrgNode); ok && len(org.Positions) > 0 {
+			return []string{org.Positions[0].SysName}
+		}
+		return nil
+	}))
+	t.Cleanup(func() {
+		fieldExtractorsMu.Lock()
+		delete(fieldExtractors, "owner")
+		fieldExtractorsMu.Unlock()
+	})
+
+	root := createQueryTree()
+	budget := budgetValue{Name: "Бюджет", Owner: "qa_lead", Amount: "1000"}
+	root.AddChild(NewNode(budget))
+
+	m, err := NewFieldMatcher("^qa_lead$", MatchOptions{}, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var matched []*Node
+	for node := range root.PreOrder() {
+		if m.Match(node) {
+			matched = append(matched, node)
+		}
+	}
+	// Извлекатель видит первую должность OrgNode, FieldStringer — поле пользовательского значения
+	if len(matched) != 2 || valueName(matched[0].Value) != "Команда тестирования" || matched[1].Value != budget {
+		t.Errorf("Unexpected matches: %v", matched)
+	}
+
+	filtered, err := root.FilterSubtreeByRegexField("^Бюджет$", FieldName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filtered == nil || len(filtered.Children) != 1 || filtered.Children[0].Value != budget {
+		t.Errorf("Expected only the custom value, got %v", filtered)
+	}
+
+	// Поле без извлекателя запрашивается только у FieldStringer
+	if names := matchedNames(t, root, "^1000$", MatchOptions{}, "budget"); len(names) != 1 {
+		t.Errorf("Expected the custom value to match an unregistered field, got %v", names)
+	}
+}
+
+func TestFilterSubtreeByRegexFieldErrors(t *testing.T) {
+	root := createQueryTree()
+
+	if filtered, err := root.FilterSubtreeByRegexField("x", "salary"); err != nil || filtered != nil {
+		t.Errorf("Expected no matches for a field no value provides, got %v, %v", filtered, err)
+	}
+	if _, err := root.FilterSubtreeByRegexField("(", FieldName); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+	if filtered, err := root.FilterSubtreeByRegexField("^нет такого$"); err != nil || filtered != nil {
+		t.Errorf("Expected nil result without error, got %v, %v", filtered, err)
+	}
+}
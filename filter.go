@@ -1,33 +1,87 @@
 package orgtree
 
 import (
+	"context"
 	"regexp"
 )
 
 // FilterSubtree возвращает новое дерево, содержащее только узлы, значения которых соответствуют условию.
 // Если узел не соответствует, но его потомки соответствуют — они "поднимаются".
 func (n *Node) FilterSubtree(predicate func(interface{}) bool) *Node {
-	var cloneIfMatched func(node *Node) *Node
+	filtered, _ := n.filterSubtree(context.Background(), predicate)
+	return filtered
+}
 
-	cloneIfMatched = func(node *Node) *Node {
-		matchingChildren := []*Node{}
-		for _, child := range node.Children {
-			if filtered := cloneIfMatched(child); filtered != nil {
-				matchingChildren = append(matchingChildren, filtered)
-			}
+// NodePredicate — условие на узел. В отличие от предиката по значению оно видит
+// положение узла в дереве: детей, родителя и глубину
+type NodePredicate interface {
+	Match(*Node) bool
+}
+
+// FilterSubtreeBy фильтрует дерево как FilterSubtree, проверяя условие на исходных узлах
+func (n *Node) FilterSubtreeBy(predicate NodePredicate) *Node {
+	filtered, _ := n.filterNodes(context.Background(), predicate.Match)
+	return filtered
+}
+
+// FilterBy возвращает первый в прямом порядке узел, удовлетворяющий условию
+func (n *Node) FilterBy(predicate NodePredicate) *Node {
+	it := NewPreOrderIterator(n)
+	for node := it.Next(); node != nil; node = it.Next() {
+		if predicate.Match(node) {
+			return node
+		}
+	}
+	return nil
+}
+
+// filterSubtree фильтрует дерево предикатом по значению узла
+func (n *Node) filterSubtree(ctx context.Context, predicate func(interface{}) bool) (*Node, error) {
+	return n.filterNodes(ctx, func(node *Node) bool { return predicate(node.Value) })
+}
+
+// filterNodes выполняет фильтрацию обходом в обратном порядке: предикат вызывается
+// для узла после его потомков, как и в рекурсивной версии
+func (n *Node) filterNodes(ctx context.Context, predicate func(*Node) bool) (*Node, error) {
+	type frame struct {
+		node     *Node
+		next     int
+		matching []*Node
+	}
+
+	var result *Node
+	stack := []frame{{node: n}}
+	for len(stack) > 0 {
+		if err := ctxDone(ctx); err != nil {
+			return nil, err
 		}
 
-		if predicate(node.Value) || len(matchingChildren) > 0 {
-			newNode := NewNode(node.Value)
-			for _, child := range matchingChildren {
-				newNode.AddChild(child)
+		top := &stack[len(stack)-1]
+		if top.next < len(top.node.Children) {
+			child := top.node.Children[top.next]
+			top.next++
+			stack = append(stack, frame{node: child})
+			continue
+		}
+
+		var filtered *Node
+		if predicate(top.node) || len(top.matching) > 0 {
+			filtered = NewNode(top.node.Value)
+			for _, child := range top.matching {
+				filtered.AddChild(child)
 			}
-			return newNode
 		}
-		return nil
+		stack = stack[:len(stack)-1]
+
+		if len(stack) == 0 {
+			result = filtered
+		} else if filtered != nil {
+			parent := &stack[len(stack)-1]
+			parent.matching = append(parent.matching, filtered)
+		}
 	}
 
-	return cloneIfMatched(n)
+	return result, nil
 }
 
 // FilterSubtreeByRegex фильтрует дерево по регулярному выражению, применяемому к значениям типа string
@@ -422,3 +422,82 @@ func BenchmarkFilterDifferentTypes(b *testing.B) {
 		})
 	}
 }
+
+// createBenchmarkTree строит дерево отдел → команда → сотрудник для сравнения
+// последовательных и параллельных обходов
+func createBenchmarkTree(numDepartments, numTeams, numEmployees int) *Node {
+	builder := NewTreeBuilder()
+	departmentType := &NodeType{ID: uuid.New(), Name: "Отдел", SysName: "department"}
+	teamType := &NodeType{ID: uuid.New(), Name: "Команда", SysName: "team"}
+	employeeType := &NodeType{ID: uuid.New(), Name: "Сотрудник", SysName: "employee"}
+
+	root := &OrgNode{ID: uuid.New(), Name: "Главный офис", SysName: "main_office", Type: departmentType}
+	builder.AddNode(root)
+	for i := 0; i < numDepartments; i++ {
+		dept := &OrgNode{ID: uuid.New(), Name: fmt.Sprintf("Отдел %d", i), SysName: fmt.Sprintf("department_%d", i), Type: departmentType}
+		builder.AddNode(dept)
+		builder.AddEdge(&Edge{FromNode: root.ID, ToNode: dept.ID})
+		for j := 0; j < numTeams; j++ {
+			team := &OrgNode{ID: uuid.New(), Name: fmt.Sprintf("Команда %d-%d", i, j), SysName: fmt.Sprintf("team_%d_%d", i, j), Type: teamType}
+			builder.AddNode(team)
+			builder.AddEdge(&Edge{FromNode: dept.ID, ToNode: team.ID})
+			for k := 0; k < numEmployees; k++ {
+				employee := &OrgNode{ID: uuid.New(), Name: fmt.Sprintf("Сотрудник %d", k), SysName: fmt.Sprintf("employee_%d", k), Type: employeeType}
+				builder.AddNode(employee)
+				builder.AddEdge(&Edge{FromNode: team.ID, ToNode: employee.ID})
+			}
+		}
+	}
+	return builder.BuildTree()
+}
+
+func BenchmarkParallelFilter(b *testing.B) {
+	tree := createBenchmarkTree(50, 50, 40)
+	predicate := func(value interface{}) bool {
+		if orgNode, ok := value.(*OrgNode); ok {
+			return strings.HasSuffix(orgNode.Name, "7")
+		}
+		return false
+	}
+
+	b.Run("Sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if tree.FilterSubtree(predicate) == nil {
+				b.Fatal("Filtered tree is nil")
+			}
+		}
+	})
+
+	for _, workers := range []int{2, 4, 8} {
+		b.Run(fmt.Sprintf("Parallel-%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if ParallelFilterSubtree(tree, predicate, workers) == nil {
+					b.Fatal("Filtered tree is nil")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParallelWalk(b *testing.B) {
+	tree := createBenchmarkTree(50, 50, 40)
+	visit := func(node *Node, depth int) {
+		if orgNode, ok := node.Value.(*OrgNode); ok {
+			_ = strings.ToLower(orgNode.Name)
+		}
+	}
+
+	b.Run("Sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tree.WalkTree(visit)
+		}
+	})
+
+	for _, workers := range []int{2, 4, 8} {
+		b.Run(fmt.Sprintf("Parallel-%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ParallelWalk(tree, workers, visit)
+			}
+		})
+	}
+}
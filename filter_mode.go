@@ -0,0 +1,207 @@
+package orgtree
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// FilterMode задает, какие узлы попадают в результат фильтрации
+type FilterMode int
+
+const (
+	// FilterKeepAncestors — найденные узлы и их предки, как в FilterSubtree
+	FilterKeepAncestors FilterMode = iota
+	// FilterKeepSubtrees — найденные узлы со всеми потомками и их предки
+	FilterKeepSubtrees
+	// FilterPrune — все дерево без найденных узлов и их поддеревьев
+	FilterPrune
+	// FilterFlatten — только найденные узлы: каждый становится ребенком ближайшего
+	// найденного предка. Если корень не найден, найденные узлы верхнего уровня
+	// собираются под общим корнем с nil-значением, как в BuildTree
+	FilterFlatten
+)
+
+func (m FilterMode) String() string {
+	switch m {
+	case FilterKeepAncestors:
+		return "keep-ancestors"
+	case FilterKeepSubtrees:
+		return "keep-subtrees"
+	case FilterPrune:
+		return "prune"
+	case FilterFlatten:
+		return "flatten"
+	}
+	return fmt.Sprintf("FilterMode(%d)", int(m))
+}
+
+// FilterResult — результат фильтрации с режимом. Root — новое дерево или nil,
+// если в результат не попал ни один узел
+type FilterResult struct {
+	Root    *Node
+	matched map[*Node]bool
+	source  map[*Node]*Node
+}
+
+// Matched сообщает, что узел результата соответствует условию
+func (r *FilterResult) Matched(n *Node) bool {
+	return r.matched[n]
+}
+
+// IsContext сообщает, что узел результата оставлен только для контекста:
+// это предок, потомок найденного узла или общий корень, но сам он условию не соответствует
+func (r *FilterResult) IsContext(n *Node) bool {
+	if n == nil || r.matched[n] {
+		return false
+	}
+	_, ok := r.source[n]
+	return ok || n == r.Root
+}
+
+// Source возвращает узел исходного дерева, из которого скопирован узел результата.
+// Для общего корня FilterFlatten и чужих узлов возвращается nil
+func (r *FilterResult) Source(n *Node) *Node {
+	return r.source[n]
+}
+
+// MatchedNodes возвращает найденные узлы результата в порядке прямого обхода
+func (r *FilterResult) MatchedNodes() []*Node {
+	if r.Root == nil {
+		return nil
+	}
+	var nodes []*Node
+	it := NewPreOrderIterator(r.Root)
+	for node := it.Next(); node != nil; node = it.Next() {
+		if r.matched[node] {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// PrintTree выводит результат в stdout, отмечая найденные узлы звездочкой
+func (r *FilterResult) PrintTree() {
+	r.WriteTree(os.Stdout)
+}
+
+// WriteTree выводит результат в w как Node.WriteTree, отмечая найденные узлы звездочкой
+func (r *FilterResult) WriteTree(w io.Writer) error {
+	if r.Root == nil {
+		return nil
+	}
+	return r.Root.writeTree(w, func(node *Node) string {
+		label := fmt.Sprint(node.Value)
+		if _, ok := valueID(node.Value); ok {
+			label = valueName(node.Value)
+		}
+		if r.matched[node] {
+			return label + " *"
+		}
+		return label
+	})
+}
+
+// FilterSubtreeMode фильтрует дерево предикатом по значению в заданном режиме
+func (n *Node) FilterSubtreeMode(predicate func(interface{}) bool, mode FilterMode) *FilterResult {
+	return n.filterMode(func(node *Node) bool { return predicate(node.Value) }, mode)
+}
+
+// FilterSubtreeByMode фильтрует дерево условием на узлы в заданном режиме
+func (n *Node) FilterSubtreeByMode(predicate NodePredicate, mode FilterMode) *FilterResult {
+	return n.filterMode(predicate.Match, mode)
+}
+
+// Флаги узлов исходного дерева для filterMode
+const (
+	flagMatched  uint8 = 1 << iota // узел соответствует условию
+	flagHasMatch                   // в поддереве узла есть найденный узел
+)
+
+// filterFrame — узел исходного дерева, родитель для его копии и признак того,
+// что узел лежит внутри найденного поддерева
+type filterFrame struct {
+	src       *Node
+	dst       *Node
+	inMatched bool
+}
+
+// filterMode вызывает предикат в обратном порядке, как filterNodes, а затем строит
+// результат прямым обходом по флагам узлов
+func (n *Node) filterMode(predicate func(*Node) bool, mode FilterMode) *FilterResult {
+	result := &FilterResult{matched: map[*Node]bool{}, source: map[*Node]*Node{}}
+	if n == nil {
+		return result
+	}
+
+	flags := map[*Node]uint8{}
+	it := NewPostOrderIterator(n)
+	for node := it.Next(); node != nil; node = it.Next() {
+		var f uint8
+		if predicate(node) {
+			f = flagMatched | flagHasMatch
+		}
+		for _, child := range node.Children {
+			if flags[child]&flagHasMatch != 0 {
+				f |= flagHasMatch
+				break
+			}
+		}
+		flags[node] = f
+	}
+
+	// copyNode копирует узел в результат и подвешивает к parent, если он есть
+	copyNode := func(src, parent *Node) *Node {
+		dst := NewNode(src.Value)
+		result.source[dst] = src
+		if flags[src]&flagMatched != 0 {
+			result.matched[dst] = true
+		}
+		if parent != nil {
+			parent.AddChild(dst)
+		} else {
+			result.Root = dst
+		}
+		return dst
+	}
+
+	stack := []filterFrame{{src: n}}
+	if mode == FilterFlatten && flags[n] == flagHasMatch {
+		result.Root = NewNode(nil)
+		stack[0].dst = result.Root
+	}
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		f := flags[top.src]
+		matched := f&flagMatched != 0
+
+		var keep bool
+		switch mode {
+		case FilterKeepSubtrees:
+			keep = top.inMatched || f&flagHasMatch != 0
+		case FilterPrune:
+			keep = !matched
+		case FilterFlatten:
+			keep = matched
+		default:
+			keep = f&flagHasMatch != 0
+		}
+
+		next := top.dst
+		if keep {
+			next = copyNode(top.src, top.dst)
+		} else if mode != FilterFlatten || f&flagHasMatch == 0 {
+			// Пропущенный узел в режиме FilterFlatten пропускает к найденным потомкам
+			continue
+		}
+
+		// Дети кладутся в обратном порядке, чтобы сохранить исходный порядок
+		for i := len(top.src.Children) - 1; i >= 0; i-- {
+			stack = append(stack, filterFrame{src: top.src.Children[i], dst: next, inMatched: top.inMatched || matched})
+		}
+	}
+
+	return result
+}
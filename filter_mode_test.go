@@ -0,0 +1,166 @@
+package orgtree
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// outline описывает результат фильтрации построчно: отступ по глубине, имя узла
+// («-» для nil-значения) и «*» у найденных узлов
+func outline(r *FilterResult) []string {
+	var lines []string
+	var visit func(n *Node, depth int)
+	visit = func(n *Node, depth int) {
+		name := "-"
+		if n.Value != nil {
+			name = valueName(n.Value)
+		}
+		if r.Matched(n) {
+			name += "*"
+		}
+		lines = append(lines, strings.Repeat(" ", depth)+name)
+		for _, c := range n.Children {
+			visit(c, depth+1)
+		}
+	}
+	if r.Root != nil {
+		visit(r.Root, 0)
+	}
+	return lines
+}
+
+func sysNameIs(sysName string) func(interface{}) bool {
+	return func(v interface{}) bool { return valueSysName(v) == sysName }
+}
+
+func TestFilterSubtreeMode(t *testing.T) {
+	root := createQueryTree()
+	isTeam := func(v interface{}) bool {
+		typ := valueType(v)
+		return typ != nil && typ.SysName == "team"
+	}
+
+	tests := []struct {
+		name      string
+		predicate func(interface{}) bool
+		mode      FilterMode
+		expected  []string
+	}{
+		{"keep ancestors", sysNameIs("mobile_team"), FilterKeepAncestors, []string{
+			"-", " Главный офис", "  IT отдел", "   Команда разработки", "    Mobile команда*",
+		}},
+		{"keep subtrees", sysNameIs("dev_team"), FilterKeepSubtrees, []string{
+			"-", " Главный офис", "  IT отдел", "   Команда разработки*", "    Mobile команда", "    Иван",
+		}},
+		{"keep subtrees marks nested matches", isTeam, FilterKeepSubtrees, []string{
+			"-", " Главный офис", "  IT отдел", "   Команда разработки*", "    Mobile команда*", "    Иван",
+			"   Команда тестирования*", "  HR отдел", "   Рекрутинг*",
+		}},
+		{"prune", sysNameIs("dev_team"), FilterPrune, []string{
+			"-", " Главный офис", "  IT отдел", "   Команда тестирования", "  HR отдел", "   Рекрутинг",
+		}},
+		{"flatten", isTeam, FilterFlatten, []string{
+			"-", " Команда разработки*", "  Mobile команда*", " Команда тестирования*", " Рекрутинг*",
+		}},
+		{"flatten single match", sysNameIs("qa_team"), FilterFlatten, []string{
+			"-", " Команда тестирования*",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := outline(root.FilterSubtreeMode(tt.predicate, tt.mode))
+			if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("Expected\n%s\ngot\n%s", strings.Join(tt.expected, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestFilterSubtreeModeMatchesFilterSubtree(t *testing.T) {
+	root := createTestTree()
+	predicate := func(v interface{}) bool {
+		org, ok := v.(*OrgNode)
+		return ok && strings.Contains(org.Name, "Team")
+	}
+
+	expected, _ := root.FilterSubtree(predicate).ToJSON()
+	got, _ := root.FilterSubtreeMode(predicate, FilterKeepAncestors).Root.ToJSON()
+	if !bytes.Equal(expected, got) {
+		t.Errorf("FilterKeepAncestors differs from FilterSubtree:\n%s\n%s", expected, got)
+	}
+}
+
+func TestFilterResultMarks(t *testing.T) {
+	root := createQueryTree()
+	office := root.Children[0]
+
+	r := root.FilterSubtreeMode(sysNameIs("it_department"), FilterKeepSubtrees)
+	matched := r.MatchedNodes()
+	if len(matched) != 1 || valueName(matched[0].Value) != "IT отдел" {
+		t.Fatalf("Expected IT отдел as the only match, got %v", orgNames(matched))
+	}
+	if r.Source(matched[0]) != office.Children[0] {
+		t.Error("Source must point to the original node")
+	}
+	if matched[0] == office.Children[0] || matched[0].Parent() == office {
+		t.Error("Result must not share nodes with the source tree")
+	}
+	if !r.IsContext(r.Root) || !r.IsContext(matched[0].Children[0]) || r.IsContext(matched[0]) {
+		t.Error("Ancestors and descendants of a match must be context nodes")
+	}
+	if r.Matched(office) || r.IsContext(office) {
+		t.Error("Nodes of the source tree are not part of the result")
+	}
+
+	// Общий корень FilterFlatten — контекст без исходного узла
+	office.Value = &OrgNode{Name: "Главный офис", SysName: "main_office"}
+	flat := office.FilterSubtreeMode(sysNameIs("qa_team"), FilterFlatten)
+	if flat.Root.Value != nil || flat.Source(flat.Root) != nil || !flat.IsContext(flat.Root) {
+		t.Error("Expected a synthetic context root")
+	}
+
+	// Корень найден — он и становится корнем результата
+	flat = office.FilterSubtreeMode(func(v interface{}) bool { return valueSysName(v) != "" }, FilterFlatten)
+	if flat.Root.Value != office.Value || !flat.Matched(flat.Root) {
+		t.Error("Expected the matching root to be kept")
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteTree(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "IT отдел *") || strings.Contains(buf.String(), "Главный офис *") {
+		t.Errorf("Unexpected marks:\n%s", buf.String())
+	}
+}
+
+func TestFilterSubtreeModeNoMatches(t *testing.T) {
+	root := createQueryTree()
+	none := func(interface{}) bool { return false }
+
+	for _, mode := range []FilterMode{FilterKeepAncestors, FilterKeepSubtrees, FilterFlatten} {
+		if r := root.FilterSubtreeMode(none, mode); r.Root != nil || len(r.MatchedNodes()) != 0 {
+			t.Errorf("%s: expected empty result", mode)
+		}
+	}
+
+	// Без совпадений FilterPrune возвращает копию всего дерева
+	r := root.FilterSubtreeMode(none, FilterPrune)
+	if r.Root == nil || !bytes.Equal(r.Root.Hash(), root.Hash()) {
+		t.Error("Expected a full copy")
+	}
+	if r := root.FilterSubtreeMode(func(interface{}) bool { return true }, FilterPrune); r.Root != nil {
+		t.Error("Pruning the root must yield an empty result")
+	}
+}
+
+func TestFilterSubtreeByMode(t *testing.T) {
+	root := createQueryTree()
+	r := root.FilterSubtreeByMode(leafPredicate{}, FilterFlatten)
+	assertNames(t, orgNames(r.Root.Children), "Mobile команда", "Иван", "Команда тестирования", "Рекрутинг")
+	if FilterFlatten.String() != "flatten" || FilterMode(42).String() != "FilterMode(42)" {
+		t.Error("Unexpected FilterMode names")
+	}
+}
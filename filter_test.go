@@ -266,3 +266,22 @@ func TestFilterSubtree(t *testing.T) {
 		}
 	})
 }
+
+// leafPredicate — условие, которому нужен сам узел, а не только значение
+type leafPredicate struct{}
+
+func (leafPredicate) Match(n *Node) bool { return len(n.Children) == 0 }
+
+func TestFilterSubtreeBy(t *testing.T) {
+	root := createTestTree()
+
+	filtered := root.FilterSubtreeBy(leafPredicate{})
+	assertFilteredTree(t, filtered, "Engineering", 2)
+	if len(filtered.Children[0].Children) != 1 {
+		t.Errorf("Expected John Doe under Backend Team, got %d children", len(filtered.Children[0].Children))
+	}
+
+	if node := root.FilterBy(leafPredicate{}); node == nil || node.Value.(*OrgNode).Name != "John Doe" {
+		t.Errorf("Expected FilterBy to return John Doe, got %v", node)
+	}
+}
@@ -0,0 +1,130 @@
+package orgtree
+
+import (
+	"reflect"
+	"sort"
+)
+
+// FindOrder задает порядок результатов FindAll
+type FindOrder int
+
+const (
+	// FindPreOrder — прямой обход (по умолчанию)
+	FindPreOrder FindOrder = iota
+	// FindBFS — обход в ширину: сначала узлы ближе к корню
+	FindBFS
+	// FindByDepth — сначала самые глубокие узлы; при равной глубине — в прямом порядке
+	FindByDepth
+)
+
+// FindOptions задает порядок и ограничение числа результатов FindAll
+type FindOptions struct {
+	Order FindOrder
+	// Limit — максимальное число результатов, 0 — без ограничения.
+	// Ограничение применяется после упорядочивания
+	Limit int
+}
+
+// Match — найденный узел с путем от корня поиска
+type Match struct {
+	Node *Node
+	// Path — узлы от корня поиска до Node включительно
+	Path []*Node
+	// Depth — глубина относительно корня поиска, у самого корня 0
+	Depth int
+}
+
+// FindAll возвращает все узлы, значения которых удовлетворяют предикату, в прямом порядке
+func (n *Node) FindAll(predicate func(interface{}) bool) []Match {
+	return n.FindAllWithOptions(predicate, FindOptions{})
+}
+
+// FindAllWithOptions возвращает найденные узлы в порядке opts.Order, не больше opts.Limit.
+// Путь строится по обходу, а не по ссылкам на родителя, поэтому корректен и для
+// деревьев, собранных без AddChild
+func (n *Node) FindAllWithOptions(predicate func(interface{}) bool, opts FindOptions) []Match {
+	if n == nil {
+		return nil
+	}
+	// В прямом порядке можно остановиться на лимите, иначе нужны все совпадения
+	limit := 0
+	if opts.Order == FindPreOrder {
+		limit = opts.Limit
+	}
+
+	var matches []Match
+	var path []*Node
+	stack := []depthEntry{{node: n}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		path = append(path[:top.depth], top.node)
+		if predicate(top.node.Value) {
+			matches = append(matches, Match{
+				Node:  top.node,
+				Path:  append([]*Node(nil), path...),
+				Depth: top.depth,
+			})
+			if limit > 0 && len(matches) == limit {
+				return matches
+			}
+		}
+		for i := len(top.node.Children) - 1; i >= 0; i-- {
+			stack = append(stack, depthEntry{node: top.node.Children[i], depth: top.depth + 1})
+		}
+	}
+
+	// Устойчивая сортировка сохраняет прямой порядок на одной глубине,
+	// что совпадает с порядком обхода в ширину
+	switch opts.Order {
+	case FindBFS:
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].Depth < matches[j].Depth })
+	case FindByDepth:
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].Depth > matches[j].Depth })
+	}
+	if opts.Limit > 0 && len(matches) > opts.Limit {
+		matches = matches[:opts.Limit]
+	}
+	return matches
+}
+
+// FindFunc возвращает первый в прямом порядке узел, значение которого равно value
+// по функции equal. Если equal равна nil, используется сравнение как в Find
+func (n *Node) FindFunc(value interface{}, equal func(a, b interface{}) bool) *Node {
+	if equal == nil {
+		equal = valuesEqual
+	}
+	it := NewPreOrderIterator(n)
+	for node := it.Next(); node != nil; node = it.Next() {
+		if equal(node.Value, value) {
+			return node
+		}
+	}
+	return nil
+}
+
+// valuesEqual сравнивает значения через ==, а несравнимые значения (карты и срезы,
+// например из FromJSON) — через reflect.DeepEqual, вместо паники
+func valuesEqual(a, b interface{}) (equal bool) {
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) {
+		return false
+	}
+	if ta == nil {
+		return true
+	}
+	if !ta.Comparable() {
+		return reflect.DeepEqual(a, b)
+	}
+	switch ta.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Interface:
+		// Сравнимый тип может содержать несравнимое значение в поле-интерфейсе
+		defer func() {
+			if recover() != nil {
+				equal = reflect.DeepEqual(a, b)
+			}
+		}()
+	}
+	return a == b
+}
@@ -0,0 +1,152 @@
+package orgtree
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// isTeamValue выбирает команды дерева createQueryTree
+func isTeamValue(v interface{}) bool {
+	t := valueType(v)
+	return t != nil && t.SysName == "team"
+}
+
+func matchNames(matches []Match) []string {
+	nodes := make([]*Node, len(matches))
+	for i, m := range matches {
+		nodes[i] = m.Node
+	}
+	return orgNames(nodes)
+}
+
+func TestFindAll(t *testing.T) {
+	root := createQueryTree()
+
+	matches := root.FindAll(isTeamValue)
+	assertNames(t, matchNames(matches), "Команда разработки", "Mobile команда", "Команда тестирования", "Рекрутинг")
+
+	mobile := matches[1]
+	if mobile.Depth != 4 || len(mobile.Path) != 5 {
+		t.Fatalf("Expected depth 4 and path of 5 nodes, got %d and %d", mobile.Depth, len(mobile.Path))
+	}
+	if mobile.Path[0] != root || mobile.Path[4] != mobile.Node {
+		t.Error("Path must start at the search root and end at the match")
+	}
+	assertNames(t, orgNames(mobile.Path[1:]), "Главный офис", "IT отдел", "Команда разработки", "Mobile команда")
+
+	// Пути не разделяют общий буфер
+	assertNames(t, orgNames(matches[2].Path[1:]), "Главный офис", "IT отдел", "Команда тестирования")
+
+	// Глубина и путь считаются от корня поиска
+	it := root.Children[0].Children[0]
+	sub := it.FindAll(isTeamValue)
+	if sub[0].Depth != 1 || sub[0].Path[0] != it {
+		t.Errorf("Expected depth relative to the search root, got %d", sub[0].Depth)
+	}
+
+	if found := root.FindAll(func(interface{}) bool { return false }); found != nil {
+		t.Errorf("Expected no matches, got %v", matchNames(found))
+	}
+}
+
+func TestFindAllWithOptions(t *testing.T) {
+	root := createQueryTree()
+
+	tests := []struct {
+		name     string
+		opts     FindOptions
+		expected []string
+	}{
+		{"pre-order limit", FindOptions{Limit: 2}, []string{"Команда разработки", "Mobile команда"}},
+		{"bfs", FindOptions{Order: FindBFS}, []string{"Команда разработки", "Команда тестирования", "Рекрутинг", "Mobile команда"}},
+		{"bfs limit", FindOptions{Order: FindBFS, Limit: 3}, []string{"Команда разработки", "Команда тестирования", "Рекрутинг"}},
+		{"by depth", FindOptions{Order: FindByDepth}, []string{"Mobile команда", "Команда разработки", "Команда тестирования", "Рекрутинг"}},
+		{"by depth limit", FindOptions{Order: FindByDepth, Limit: 1}, []string{"Mobile команда"}},
+		{"limit above count", FindOptions{Limit: 10}, []string{"Команда разработки", "Mobile команда", "Команда тестирования", "Рекрутинг"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertNames(t, matchNames(root.FindAllWithOptions(isTeamValue, tt.opts)), tt.expected...)
+		})
+	}
+}
+
+func TestFindAllWithoutParentLinks(t *testing.T) {
+	// Дерево собрано без AddChild, ссылок на родителя нет
+	leaf := &Node{Value: "leaf"}
+	root := &Node{Value: "root", Children: []*Node{{Value: "mid", Children: []*Node{leaf}}}}
+
+	matches := root.FindAll(func(v interface{}) bool { return v == "leaf" })
+	if len(matches) != 1 || len(matches[0].Path) != 3 || matches[0].Path[1].Value != "mid" {
+		t.Errorf("Unexpected match: %+v", matches)
+	}
+}
+
+// labeled — сравнимый тип с полем-интерфейсом, в котором может оказаться карта
+type labeled struct {
+	Label interface{}
+}
+
+func TestFindUncomparableValues(t *testing.T) {
+	tree, err := FromJSON([]byte(`{"value": {"name": "root"}, "children": [
+		{"value": {"name": "a", "tags": ["x"]}},
+		{"value": ["b", 1]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Раньше сравнение карт через == приводило к панике
+	found := tree.Find(map[string]interface{}{"name": "a", "tags": []interface{}{"x"}})
+	if found == nil || found != tree.Children[0] {
+		t.Errorf("Expected to find the map value, got %v", found)
+	}
+	if found := tree.Find([]interface{}{"b", float64(1)}); found != tree.Children[1] {
+		t.Errorf("Expected to find the slice value, got %v", found)
+	}
+	if found := tree.Find(map[string]interface{}{"name": "missing"}); found != nil {
+		t.Errorf("Expected nil, got %v", found)
+	}
+	if found, err := tree.FindCtx(context.Background(), map[string]interface{}{"name": "root"}); err != nil || found != tree {
+		t.Errorf("FindCtx: expected root, got %v, %v", found, err)
+	}
+
+	holder := NewNode(labeled{Label: "x"})
+	withMap := NewNode(labeled{Label: map[string]int{"k": 1}})
+	holder.AddChild(withMap)
+	if found := holder.Find(labeled{Label: map[string]int{"k": 1}}); found != withMap {
+		t.Errorf("Expected a struct holding a map to be compared by content, got %v", found)
+	}
+
+	// Указатели по-прежнему сравниваются по адресу
+	root := createQueryTree()
+	office := root.Children[0]
+	if root.Find(office.Value) != office {
+		t.Error("Expected pointer values to be found by identity")
+	}
+	if root.Find(&OrgNode{Name: "Главный офис", SysName: "main_office"}) != nil {
+		t.Error("Equal pointee must not match a different pointer")
+	}
+}
+
+func TestFindFunc(t *testing.T) {
+	root := createQueryTree()
+
+	byName := func(a, b interface{}) bool {
+		name, ok := b.(string)
+		return ok && strings.EqualFold(valueName(a), name)
+	}
+	if found := root.FindFunc("mobile КОМАНДА", byName); found == nil || valueName(found.Value) != "Mobile команда" {
+		t.Errorf("Expected Mobile команда, got %v", found)
+	}
+	if found := root.FindFunc("нет такого", byName); found != nil {
+		t.Errorf("Expected nil, got %v", found)
+	}
+
+	office := root.Children[0]
+	if found := root.FindFunc(office.Value, nil); found != office {
+		t.Error("nil equality must behave like Find")
+	}
+}
@@ -0,0 +1,91 @@
+package orgtree
+
+// MapTree возвращает новое дерево той же формы, значения которого получены функцией fn.
+// fn вызывается для узлов исходного дерева в прямом порядке; порядок детей сохраняется.
+func MapTree(root *Node, fn func(*Node) interface{}) *Node {
+	if root == nil {
+		return nil
+	}
+
+	type pending struct {
+		src, parent *Node
+	}
+
+	var result *Node
+	stack := []pending{{src: root}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		dst := NewNode(fn(p.src))
+		if p.parent == nil {
+			result = dst
+		} else {
+			p.parent.AddChild(dst)
+		}
+		dst.Children = make([]*Node, 0, len(p.src.Children))
+		for i := len(p.src.Children) - 1; i >= 0; i-- {
+			stack = append(stack, pending{p.src.Children[i], dst})
+		}
+	}
+	return result
+}
+
+// FoldUp вычисляет значение снизу вверх: fn получает узел и результаты его детей
+// в порядке Children и возвращает результат узла. Возвращается результат корня.
+// Срез children переиспользуется и действителен только во время вызова fn.
+func FoldUp[R any](root *Node, fn func(node *Node, children []R) R) R {
+	if root == nil {
+		var zero R
+		return zero
+	}
+
+	stack := []postOrderFrame{{node: root}}
+	// results содержит готовые результаты детей узлов, находящихся в стеке
+	var results []R
+	for {
+		top := &stack[len(stack)-1]
+		if top.next < len(top.node.Children) {
+			child := top.node.Children[top.next]
+			top.next++
+			stack = append(stack, postOrderFrame{node: child})
+			continue
+		}
+
+		start := len(results) - len(top.node.Children)
+		result := fn(top.node, results[start:])
+		results = append(results[:start], result)
+		stack = stack[:len(stack)-1]
+		if len(stack) == 0 {
+			return result
+		}
+	}
+}
+
+// FoldDown распространяет значение сверху вниз: результат узла вычисляется fn
+// из самого узла и результата его родителя, для корня вместо результата родителя передается initial.
+// Возвращает результаты всех узлов поддерева.
+func FoldDown[R any](root *Node, initial R, fn func(node *Node, parent R) R) map[*Node]R {
+	results := map[*Node]R{}
+	if root == nil {
+		return results
+	}
+
+	type pending struct {
+		node   *Node
+		parent R
+	}
+
+	stack := []pending{{root, initial}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		result := fn(p.node, p.parent)
+		results[p.node] = result
+		for i := len(p.node.Children) - 1; i >= 0; i-- {
+			stack = append(stack, pending{p.node.Children[i], result})
+		}
+	}
+	return results
+}
@@ -0,0 +1,97 @@
+package orgtree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMapTree(t *testing.T) {
+	root, _ := createIndexedTree()
+
+	type dto struct {
+		Title string
+	}
+	var order []string
+	mapped := MapTree(root, func(n *Node) interface{} {
+		order = append(order, valueName(n.Value))
+		return dto{Title: strings.ToUpper(valueName(n.Value))}
+	})
+
+	assertNames(t, order, "", "IT отдел", "Команда разработки", "Иван", "Команда тестирования", "HR отдел")
+	if len(mapped.Children) != 2 || mapped.Children[0].Value != (dto{"IT ОТДЕЛ"}) {
+		t.Fatalf("Unexpected mapped tree: %v", mapped.Children)
+	}
+	it := mapped.Children[0]
+	if len(it.Children) != 2 || it.Children[1].Value != (dto{"КОМАНДА ТЕСТИРОВАНИЯ"}) {
+		t.Errorf("Expected children order to be preserved, got %v", it.Children)
+	}
+	if it.Children[0].Children[0].Parent() != it.Children[0] {
+		t.Error("Expected parent pointers in mapped tree")
+	}
+	if _, ok := root.Children[0].Value.(*OrgNode); !ok {
+		t.Error("Source tree must not change")
+	}
+	if MapTree(nil, func(*Node) interface{} { return nil }) != nil {
+		t.Error("Expected nil for nil root")
+	}
+}
+
+func TestFoldUpHeadcount(t *testing.T) {
+	root, _ := createIndexedTree()
+	qa := root.Children[0].Children[1]
+	qa.AddChild(NewNode(&EmployeeNode{Name: "Петр"}))
+	qa.AddChild(NewNode(&EmployeeNode{Name: "Анна"}))
+
+	headcount := map[string]int{}
+	total := FoldUp(root, func(n *Node, children []int) int {
+		count := 0
+		if _, ok := n.Value.(*EmployeeNode); ok {
+			count = 1
+		}
+		for _, c := range children {
+			count += c
+		}
+		headcount[valueName(n.Value)] = count
+		return count
+	})
+
+	if total != 3 {
+		t.Errorf("Expected total headcount 3, got %d", total)
+	}
+	expected := map[string]int{"IT отдел": 3, "HR отдел": 0, "Команда разработки": 1, "Команда тестирования": 2}
+	for name, count := range expected {
+		if headcount[name] != count {
+			t.Errorf("%s: expected %d, got %d", name, count, headcount[name])
+		}
+	}
+
+	// Результаты детей передаются в порядке Children
+	names := FoldUp(root.Children[0], func(n *Node, children []string) string {
+		return valueName(n.Value) + "(" + strings.Join(children, ",") + ")"
+	})
+	if names != "IT отдел(Команда разработки(Иван()),Команда тестирования(Петр(),Анна()))" {
+		t.Errorf("Unexpected fold order: %s", names)
+	}
+}
+
+func TestFoldDown(t *testing.T) {
+	root, _ := createIndexedTree()
+
+	paths := FoldDown(root.Children[0], "", func(n *Node, parent string) string {
+		if parent == "" {
+			return valueName(n.Value)
+		}
+		return parent + " / " + valueName(n.Value)
+	})
+
+	ivan := root.Children[0].Children[0].Children[0]
+	if paths[ivan] != "IT отдел / Команда разработки / Иван" {
+		t.Errorf("Unexpected inherited path: %q", paths[ivan])
+	}
+	if len(paths) != 4 {
+		t.Errorf("Expected results for 4 nodes, got %d", len(paths))
+	}
+	if _, ok := paths[root]; ok {
+		t.Error("Expected only nodes of the subtree")
+	}
+}
@@ -0,0 +1,94 @@
+package generic
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/arsants/orgtree"
+)
+
+// FromNode строит типизированное дерево из orgtree.Node.
+// Значение nil превращается в нулевое значение T (так устроен корень-заглушка TreeBuilder),
+// значение другого типа приводит к ошибке.
+func FromNode[T any](root *orgtree.Node) (*Node[T], error) {
+	if root == nil {
+		return nil, nil
+	}
+
+	type pair struct {
+		src *orgtree.Node
+		dst *Node[T]
+	}
+
+	// convert создает узел без детей, приводя значение к T
+	convert := func(node *orgtree.Node) (*Node[T], error) {
+		var value T
+		if node.Value != nil {
+			v, ok := node.Value.(T)
+			if !ok {
+				return nil, fmt.Errorf("generic: значение %v имеет тип %T, ожидался %T", node.Value, node.Value, value)
+			}
+			value = v
+		}
+		return NewNode(value), nil
+	}
+
+	result, err := convert(root)
+	if err != nil {
+		return nil, err
+	}
+	stack := []pair{{root, result}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, child := range p.src.Children {
+			converted, err := convert(child)
+			if err != nil {
+				return nil, err
+			}
+			p.dst.AddChild(converted)
+			stack = append(stack, pair{child, converted})
+		}
+	}
+	return result, nil
+}
+
+// ToNode строит orgtree.Node с теми же значениями и структурой.
+// Нулевые указатели, карты, срезы и интерфейсы сохраняются как nil без типа,
+// поэтому корень-заглушка TreeBuilder переживает преобразование туда и обратно
+func (n *Node[T]) ToNode() *orgtree.Node {
+	type pair struct {
+		src *Node[T]
+		dst *orgtree.Node
+	}
+
+	root := orgtree.NewNode(untypedValue(n.Value))
+	stack := []pair{{n, root}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, child := range p.src.Children {
+			converted := orgtree.NewNode(untypedValue(child.Value))
+			p.dst.AddChild(converted)
+			stack = append(stack, pair{child, converted})
+		}
+	}
+	return root
+}
+
+// untypedValue возвращает nil вместо типизированного nil, чтобы проверки
+// Value == nil в orgtree работали так же, как для исходного дерева
+func untypedValue(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			return nil
+		}
+	}
+	return value
+}
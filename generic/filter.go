@@ -0,0 +1,41 @@
+package generic
+
+// FilterSubtree возвращает новое дерево, содержащее только узлы, значения которых соответствуют условию.
+// Если узел не соответствует, но его потомки соответствуют — они "поднимаются".
+func (n *Node[T]) FilterSubtree(predicate func(T) bool) *Node[T] {
+	// Обход в обратном порядке: предикат вызывается для узла после его потомков
+	type frame struct {
+		node     *Node[T]
+		next     int
+		matching []*Node[T]
+	}
+
+	var result *Node[T]
+	stack := []frame{{node: n}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.next < len(top.node.Children) {
+			child := top.node.Children[top.next]
+			top.next++
+			stack = append(stack, frame{node: child})
+			continue
+		}
+
+		var filtered *Node[T]
+		if predicate(top.node.Value) || len(top.matching) > 0 {
+			filtered = NewNode(top.node.Value)
+			for _, child := range top.matching {
+				filtered.AddChild(child)
+			}
+		}
+		stack = stack[:len(stack)-1]
+
+		if len(stack) == 0 {
+			result = filtered
+		} else if filtered != nil {
+			parent := &stack[len(stack)-1]
+			parent.matching = append(parent.matching, filtered)
+		}
+	}
+	return result
+}
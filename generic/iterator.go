@@ -0,0 +1,91 @@
+package generic
+
+type Iterator[T any] interface {
+	Next() *Node[T]
+}
+
+// --- PreOrder Iterator ---
+
+type PreOrderIterator[T any] struct {
+	stack []*Node[T]
+}
+
+func NewPreOrderIterator[T any](root *Node[T]) *PreOrderIterator[T] {
+	return &PreOrderIterator[T]{stack: []*Node[T]{root}}
+}
+
+func (it *PreOrderIterator[T]) Next() *Node[T] {
+	if len(it.stack) == 0 {
+		return nil
+	}
+
+	node := it.stack[len(it.stack)-1]
+	it.stack = it.stack[:len(it.stack)-1]
+
+	// Добавляем детей в стек в обратном порядке
+	for i := len(node.Children) - 1; i >= 0; i-- {
+		it.stack = append(it.stack, node.Children[i])
+	}
+
+	return node
+}
+
+// --- PostOrder Iterator ---
+
+// PostOrderIterator обходит дерево в обратном порядке: дети раньше родителя.
+// Хранит только стек от корня до текущего узла.
+type PostOrderIterator[T any] struct {
+	stack []postOrderFrame[T]
+}
+
+// postOrderFrame хранит узел и номер следующего непосещенного ребенка
+type postOrderFrame[T any] struct {
+	node *Node[T]
+	next int
+}
+
+func NewPostOrderIterator[T any](root *Node[T]) *PostOrderIterator[T] {
+	return &PostOrderIterator[T]{
+		stack: []postOrderFrame[T]{{node: root}},
+	}
+}
+
+func (it *PostOrderIterator[T]) Next() *Node[T] {
+	for len(it.stack) > 0 {
+		top := &it.stack[len(it.stack)-1]
+		if top.next < len(top.node.Children) {
+			child := top.node.Children[top.next]
+			top.next++
+			it.stack = append(it.stack, postOrderFrame[T]{node: child})
+			continue
+		}
+
+		node := top.node
+		it.stack = it.stack[:len(it.stack)-1]
+		return node
+	}
+	return nil
+}
+
+// --- BFS Iterator ---
+
+type BFSIterator[T any] struct {
+	queue []*Node[T]
+}
+
+func NewBFSIterator[T any](root *Node[T]) *BFSIterator[T] {
+	return &BFSIterator[T]{queue: []*Node[T]{root}}
+}
+
+func (it *BFSIterator[T]) Next() *Node[T] {
+	if len(it.queue) == 0 {
+		return nil
+	}
+
+	node := it.queue[0]
+	it.queue = it.queue[1:]
+
+	it.queue = append(it.queue, node.Children...)
+
+	return node
+}
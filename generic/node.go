@@ -0,0 +1,23 @@
+// Package generic содержит типобезопасную версию дерева orgtree,
+// параметризованную типом значения узла.
+package generic
+
+// Node представляет узел в дереве со значением типа T
+// Поля объявлены в порядке ключей JSON orgtree.Node.ToJSON
+type Node[T any] struct {
+	Children []*Node[T] `json:"children"`
+	Value    T          `json:"value"`
+}
+
+// NewNode создает новый узел
+func NewNode[T any](value T) *Node[T] {
+	return &Node[T]{
+		Value:    value,
+		Children: []*Node[T]{},
+	}
+}
+
+// AddChild добавляет дочерний узел
+func (n *Node[T]) AddChild(child *Node[T]) {
+	n.Children = append(n.Children, child)
+}
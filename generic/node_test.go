@@ -0,0 +1,218 @@
+package generic
+
+import (
+	"testing"
+
+	"github.com/arsants/orgtree"
+	"github.com/google/uuid"
+)
+
+func createTestTree() *Node[string] {
+	root := NewNode("root")
+	child1 := NewNode("child1")
+	child2 := NewNode("child2")
+	child1.AddChild(NewNode("grandchild1"))
+	child2.AddChild(NewNode("grandchild2"))
+	root.AddChild(child1)
+	root.AddChild(child2)
+	return root
+}
+
+func collect[T any](it Iterator[T]) []T {
+	values := []T{}
+	for node := it.Next(); node != nil; node = it.Next() {
+		values = append(values, node.Value)
+	}
+	return values
+}
+
+func assertValues(t *testing.T, got, expected []string) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, got)
+			return
+		}
+	}
+}
+
+func TestIterators(t *testing.T) {
+	root := createTestTree()
+
+	assertValues(t, collect[string](NewPreOrderIterator(root)),
+		[]string{"root", "child1", "grandchild1", "child2", "grandchild2"})
+	assertValues(t, collect[string](NewPostOrderIterator(root)),
+		[]string{"grandchild1", "child1", "grandchild2", "child2", "root"})
+	assertValues(t, collect[string](NewBFSIterator(root)),
+		[]string{"root", "child1", "child2", "grandchild1", "grandchild2"})
+}
+
+func TestFindAndFilter(t *testing.T) {
+	root := createTestTree()
+
+	if node := Find(root, "grandchild2"); node == nil || node.Value != "grandchild2" {
+		t.Errorf("Find failed to find 'grandchild2', got %v", node)
+	}
+	if node := Find(root, "nonexistent"); node != nil {
+		t.Error("Find returned node for non-existent value")
+	}
+	if node := root.Filter(func(v string) bool { return len(v) > 6 }); node == nil || node.Value != "grandchild1" {
+		t.Errorf("Filter returned wrong node: %v", node)
+	}
+}
+
+func TestFilterSubtree(t *testing.T) {
+	root := createTestTree()
+
+	filtered := root.FilterSubtree(func(v string) bool { return v == "grandchild2" })
+	if filtered == nil {
+		t.Fatal("Filtered tree is nil")
+	}
+	assertValues(t, collect[string](NewPreOrderIterator(filtered)),
+		[]string{"root", "child2", "grandchild2"})
+
+	if root.FilterSubtree(func(string) bool { return false }) != nil {
+		t.Error("Expected nil when nothing matches")
+	}
+}
+
+func TestWalkTree(t *testing.T) {
+	root := createTestTree()
+
+	depths := map[string]int{}
+	root.WalkTree(func(node *Node[string], depth int) {
+		depths[node.Value] = depth
+	})
+
+	expected := map[string]int{"root": 0, "child1": 1, "child2": 1, "grandchild1": 2, "grandchild2": 2}
+	for value, depth := range expected {
+		if depths[value] != depth {
+			t.Errorf("Node %s: expected depth %d, got %d", value, depth, depths[value])
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	dept := &orgtree.OrgNode{ID: uuid.New(), Name: "IT отдел", SysName: "it_department"}
+	team := &orgtree.OrgNode{ID: uuid.New(), Name: "Команда разработки", SysName: "dev_team"}
+	root := NewNode(dept)
+	root.AddChild(NewNode(team))
+
+	data, err := root.ToJSON()
+	if err != nil {
+		t.Fatalf("Failed to serialize tree: %v", err)
+	}
+
+	restored, err := FromJSON[*orgtree.OrgNode](data)
+	if err != nil {
+		t.Fatalf("Failed to deserialize tree: %v", err)
+	}
+	if restored.Value.ID != dept.ID || restored.Value.Name != dept.Name {
+		t.Errorf("Root value mismatch: %+v", restored.Value)
+	}
+	if len(restored.Children) != 1 || restored.Children[0].Value.SysName != "dev_team" {
+		t.Fatalf("Children mismatch after round trip")
+	}
+	if restored.Children[0].Children == nil {
+		t.Error("Expected empty, non-nil children slice for leaf")
+	}
+
+	// Формат совместим с нетипизированным деревом
+	untyped, err := orgtree.FromJSON(data)
+	if err != nil {
+		t.Fatalf("orgtree.FromJSON failed: %v", err)
+	}
+	if len(untyped.Children) != 1 {
+		t.Errorf("Expected 1 child in untyped tree, got %d", len(untyped.Children))
+	}
+	if expected, _ := root.ToNode().ToJSON(); string(expected) != string(data) {
+		t.Errorf("Expected the same JSON as orgtree.Node.ToJSON:\n%s\n%s", expected, data)
+	}
+}
+
+func TestNodeAdapters(t *testing.T) {
+	builder := orgtree.NewTreeBuilder()
+	office := &orgtree.OrgNode{ID: uuid.New(), Name: "Главный офис", SysName: "main_office"}
+	it := &orgtree.OrgNode{ID: uuid.New(), Name: "IT отдел", SysName: "it_department"}
+	builder.AddNode(office)
+	builder.AddNode(it)
+	builder.AddEdge(&orgtree.Edge{FromNode: office.ID, ToNode: it.ID})
+
+	typed, err := FromNode[*orgtree.OrgNode](builder.BuildTree())
+	if err != nil {
+		t.Fatalf("FromNode failed: %v", err)
+	}
+	if typed.Value != nil {
+		t.Errorf("Expected nil value for wrapper root, got %v", typed.Value)
+	}
+	if got := typed.Children[0].Children[0].Value.SysName; got != "it_department" {
+		t.Errorf("Expected it_department, got %s", got)
+	}
+
+	back := typed.ToNode()
+	if orgNode, ok := back.Children[0].Value.(*orgtree.OrgNode); !ok || orgNode != office {
+		t.Errorf("ToNode lost value: %v", back.Children[0].Value)
+	}
+
+	// Корень-заглушка остается nil без типа: запросы и статистика работают как на исходном дереве
+	if back.Value != nil {
+		t.Errorf("Expected untyped nil root, got %#v", back.Value)
+	}
+	if nodes, err := back.Select("/main_office/it_department"); err != nil || len(nodes) != 1 {
+		t.Errorf("Expected 1 node from Select after round trip, got %d (%v)", len(nodes), err)
+	}
+	if stats := orgtree.Stats(back); stats.Nodes != 2 {
+		t.Errorf("Expected 2 nodes in Stats after round trip, got %d", stats.Nodes)
+	}
+
+	mixed := orgtree.NewNode("root")
+	mixed.AddChild(orgtree.NewNode(42))
+	if _, err := FromNode[string](mixed); err == nil {
+		t.Error("Expected error for value of wrong type")
+	}
+}
+
+func TestDeepChain(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping deep chain test in short mode")
+	}
+
+	// Вырожденная цепочка проверяет, что обходы и преобразования не рекурсивны
+	const length = 1_000_000
+	root := NewNode(0)
+	leaf := root
+	for i := 1; i < length; i++ {
+		next := NewNode(i)
+		leaf.AddChild(next)
+		leaf = next
+	}
+
+	count, maxDepth := 0, 0
+	root.WalkTree(func(_ *Node[int], depth int) {
+		count++
+		maxDepth = max(maxDepth, depth)
+	})
+	if count != length || maxDepth != length-1 {
+		t.Errorf("WalkTree: expected %d nodes down to depth %d, got %d and %d", length, length-1, count, maxDepth)
+	}
+
+	if first := NewPostOrderIterator(root).Next(); first != leaf {
+		t.Error("PostOrderIterator must start at the leaf")
+	}
+
+	filtered := root.FilterSubtree(func(v int) bool { return v == length-1 })
+	if filtered == nil || Find(filtered, length-1) == nil {
+		t.Error("FilterSubtree must keep the whole chain")
+	}
+
+	back, err := FromNode[int](root.ToNode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Find(back, length-1) == nil {
+		t.Error("Round trip must keep the whole chain")
+	}
+}
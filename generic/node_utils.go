@@ -0,0 +1,66 @@
+package generic
+
+import (
+	"encoding/json"
+)
+
+// Find ищет первый узел с данным значением.
+// Функция, а не метод: сравнение через == требует comparable-типа.
+func Find[T comparable](root *Node[T], value T) *Node[T] {
+	return root.Filter(func(v T) bool { return v == value })
+}
+
+// Filter возвращает первый узел, удовлетворяющий предикату
+func (n *Node[T]) Filter(predicate func(T) bool) *Node[T] {
+	it := NewPreOrderIterator(n)
+	for node := it.Next(); node != nil; node = it.Next() {
+		if predicate(node.Value) {
+			return node
+		}
+	}
+	return nil
+}
+
+// depthEntry хранит узел вместе с его глубиной для обхода с явным стеком
+type depthEntry[T any] struct {
+	node  *Node[T]
+	depth int
+}
+
+// WalkTree обходит дерево в прямом порядке и вызывает callback для каждого узла
+func (n *Node[T]) WalkTree(callback func(*Node[T], int)) {
+	stack := []depthEntry[T]{{node: n}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		callback(e.node, e.depth)
+
+		for i := len(e.node.Children) - 1; i >= 0; i-- {
+			stack = append(stack, depthEntry[T]{e.node.Children[i], e.depth + 1})
+		}
+	}
+}
+
+// ToJSON сериализует дерево в JSON. Формат совпадает с orgtree.Node.ToJSON
+func (n *Node[T]) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(n, "", "  ")
+}
+
+// FromJSON десериализует JSON в дерево, восстанавливая значения типа T
+func FromJSON[T any](data []byte) (*Node[T], error) {
+	var root Node[T]
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	// Приводим отсутствующие списки детей к пустым, как это делает NewNode
+	it := NewPreOrderIterator(&root)
+	for node := it.Next(); node != nil; node = it.Next() {
+		if node.Children == nil {
+			node.Children = []*Node[T]{}
+		}
+	}
+
+	return &root, nil
+}
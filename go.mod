@@ -1,5 +1,7 @@
 module github.com/arsants/orgtree
 
-go 1.21
+go 1.23.0
 
 require github.com/google/uuid v1.6.0
+
+require golang.org/x/text v0.28.0
@@ -0,0 +1,144 @@
+package orgtree
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"reflect"
+)
+
+// nodeHashTag отделяет хеши узлов дерева от других данных
+const nodeHashTag = "orgtree/node/v1"
+
+// hashEntry кеширует хеш узла вместе с тем, из чего он был вычислен
+type hashEntry struct {
+	value    interface{}
+	children []*hashEntry
+	sum      []byte
+}
+
+// SubtreeHash возвращает Merkle-хеш поддерева с корнем в node.
+// Хеш узла покрывает JSON его значения и хеши детей в их порядке:
+//
+//	sha256(tag | len(value) | value | len(children) | hash(child_1) | ... | hash(child_n))
+//
+// Длины кодируются как uint64 big-endian, поэтому разные структуры не дают одинаковую
+// последовательность байтов.
+//
+// Хеши кешируются в узлах и сбрасываются у узла и его предков при вставке, удалении
+// и перестановке детей. Кеш также считается устаревшим, если изменился сам Value
+// (присвоено другое значение) или срез Children изменен напрямую; тогда пересчитываются
+// только хеши узла и его предков. Изменение полей значения по указателю
+// (например, OrgNode.Name) не обнаруживается — после него вызовите InvalidateHash.
+func SubtreeHash(node *Node) []byte {
+	sum, _ := subtreeHash(context.Background(), node)
+	return sum
+}
+
+// subtreeHash вычисляет хеш поддерева, проверяя контекст перед каждым узлом
+func subtreeHash(ctx context.Context, node *Node) ([]byte, error) {
+	type frame struct {
+		node *Node
+		next int
+	}
+
+	stack := []frame{{node: node}}
+	for len(stack) > 0 {
+		if err := ctxDone(ctx); err != nil {
+			return nil, err
+		}
+
+		top := &stack[len(stack)-1]
+		if top.next < len(top.node.Children) {
+			child := top.node.Children[top.next]
+			top.next++
+			stack = append(stack, frame{node: child})
+			continue
+		}
+
+		if entry := top.node.hash.Load(); entry == nil || !entry.validFor(top.node) {
+			top.node.hash.Store(newHashEntry(top.node))
+		}
+		stack = stack[:len(stack)-1]
+	}
+
+	return bytes.Clone(node.hash.Load().sum), nil
+}
+
+// InvalidateHash сбрасывает кешированные хеши узла и его предков.
+// Нужен после изменения полей значения узла по указателю.
+func (n *Node) InvalidateHash() {
+	n.invalidateHashes()
+}
+
+// invalidateHashes сбрасывает кеш узла и предков. Подъем останавливается на узле
+// без кеша: его предки уже сброшены, а замкнутая в цикл цепочка проходится один раз
+func (n *Node) invalidateHashes() {
+	for node := n; node != nil && node.hash.Load() != nil; node = node.parent {
+		node.hash.Store(nil)
+	}
+}
+
+// validFor сообщает, соответствует ли кеш текущему состоянию узла.
+// Кеши детей к этому моменту уже проверены
+func (e *hashEntry) validFor(n *Node) bool {
+	if !cacheableValue(e.value) || !cacheableValue(n.Value) || e.value != n.Value {
+		return false
+	}
+	if len(e.children) != len(n.Children) {
+		return false
+	}
+	for i, child := range n.Children {
+		if e.children[i] != child.hash.Load() {
+			return false
+		}
+	}
+	return true
+}
+
+// newHashEntry вычисляет хеш узла по значению и уже вычисленным хешам детей
+func newHashEntry(n *Node) *hashEntry {
+	entry := &hashEntry{
+		value:    n.Value,
+		children: make([]*hashEntry, len(n.Children)),
+	}
+
+	h := sha256.New()
+	var length [8]byte
+
+	h.Write([]byte(nodeHashTag))
+
+	valBytes, _ := json.Marshal(n.Value)
+	binary.BigEndian.PutUint64(length[:], uint64(len(valBytes)))
+	h.Write(length[:])
+	h.Write(valBytes)
+
+	binary.BigEndian.PutUint64(length[:], uint64(len(n.Children)))
+	h.Write(length[:])
+	for i, child := range n.Children {
+		childEntry := child.hash.Load()
+		entry.children[i] = childEntry
+		h.Write(childEntry.sum)
+	}
+
+	entry.sum = h.Sum(nil)
+	return entry
+}
+
+// cacheableValue сообщает, можно ли обнаружить замену значения сравнением ==.
+// Для составных значений (map, срезы, структуры) хеш пересчитывается всегда.
+func cacheableValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Pointer, reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
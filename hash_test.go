@@ -0,0 +1,127 @@
+package orgtree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHashDistinguishesStructure(t *testing.T) {
+	// root → x → y
+	nested := NewNode("root")
+	x := NewNode("x")
+	nested.AddChild(x)
+	x.AddChild(NewNode("y"))
+
+	// root → x, y: те же значения в том же порядке обхода
+	flat := NewNode("root")
+	flat.AddChild(NewNode("x"))
+	flat.AddChild(NewNode("y"))
+
+	if nested.HashString() == flat.HashString() {
+		t.Error("Trees with different structure must have different hashes")
+	}
+
+	// Значения, склеивающиеся в одинаковые байты
+	a := NewNode("ab")
+	a.AddChild(NewNode("c"))
+	b := NewNode("a")
+	b.AddChild(NewNode("bc"))
+	if a.HashString() == b.HashString() {
+		t.Error("Value boundaries must be part of the hash")
+	}
+}
+
+func TestSubtreeHash(t *testing.T) {
+	root := createTestTree()
+	backend := root.Children[0]
+
+	if !bytes.Equal(SubtreeHash(backend), backend.Clone().Hash()) {
+		t.Error("Subtree hash must depend only on the subtree")
+	}
+	if !bytes.Equal(SubtreeHash(root), root.Hash()) {
+		t.Error("Hash must equal SubtreeHash of root")
+	}
+
+	// Хеш возвращается копией и не портит кеш
+	h := SubtreeHash(backend)
+	h[0] ^= 0xff
+	if bytes.Equal(h, SubtreeHash(backend)) {
+		t.Error("SubtreeHash must return a copy")
+	}
+}
+
+func TestHashCacheInvalidation(t *testing.T) {
+	root := createTestTree()
+	backend, frontend := root.Children[0], root.Children[1]
+
+	before := root.HashString()
+	frontendEntry := frontend.hash.Load()
+	if frontendEntry == nil {
+		t.Fatal("Expected hash to be cached")
+	}
+
+	// Повторное вычисление использует кеш
+	if root.HashString() != before || frontend.hash.Load() != frontendEntry {
+		t.Error("Unchanged subtree was rehashed")
+	}
+
+	// Структурное изменение сбрасывает кеш предков, но не соседей
+	backend.AddChild(NewNode("intern"))
+	if root.hash.Load() != nil || backend.hash.Load() != nil {
+		t.Error("AddChild must reset cached hashes of ancestors")
+	}
+	afterAdd := root.HashString()
+	if afterAdd == before {
+		t.Error("Hash did not change after AddChild")
+	}
+	if frontend.hash.Load() != frontendEntry {
+		t.Error("Sibling subtree was rehashed")
+	}
+
+	// Изменение порядка детей
+	if err := root.SwapChildren(0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if root.HashString() == afterAdd {
+		t.Error("Hash did not change after reordering children")
+	}
+	if err := root.SwapChildren(0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if root.HashString() != afterAdd {
+		t.Error("Hash must be restored after restoring order")
+	}
+
+	// Изменение поля по указателю требует InvalidateHash
+	employee := backend.Children[0]
+	employee.Value.(*OrgNode).Name = "Jane Doe"
+	if root.HashString() != afterAdd {
+		t.Error("In-place value change is not expected to be detected without InvalidateHash")
+	}
+	employee.InvalidateHash()
+	if root.hash.Load() != nil || backend.hash.Load() != nil {
+		t.Error("InvalidateHash must reset ancestors")
+	}
+	if root.HashString() == afterAdd {
+		t.Error("Hash did not change after InvalidateHash")
+	}
+	if frontend.hash.Load() != frontendEntry {
+		t.Error("Sibling subtree was rehashed after InvalidateHash")
+	}
+
+	// Прямое изменение среза Children обнаруживается
+	beforeSlice := root.HashString()
+	frontend.Children = append(frontend.Children, NewNode("direct"))
+	if root.HashString() == beforeSlice {
+		t.Error("Hash did not change after direct Children modification")
+	}
+}
+
+func TestHashOfJSONValues(t *testing.T) {
+	root := NewNode(map[string]interface{}{"name": "a"})
+	before := root.HashString()
+	root.Value.(map[string]interface{})["name"] = "b"
+	if root.HashString() == before {
+		t.Error("Composite values must be rehashed on every call")
+	}
+}
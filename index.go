@@ -0,0 +1,297 @@
+package orgtree
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// treeObserver получает уведомления об изменении состава поддерева
+type treeObserver interface {
+	nodeAttached(child *Node)
+	nodeDetached(child *Node)
+}
+
+// notifyAttached сообщает наблюдателям предков n о присоединении child.
+// Узлы вне индексированных деревьев не поднимаются к корню
+func (n *Node) notifyAttached(child *Node) {
+	if n.watched.Load() == 0 {
+		return
+	}
+	n.forEachAncestor(func(node *Node) {
+		for _, o := range node.observers {
+			o.nodeAttached(child)
+		}
+	})
+}
+
+// notifyDetached сообщает наблюдателям предков n об отсоединении child
+func (n *Node) notifyDetached(child *Node) {
+	if n.watched.Load() == 0 {
+		return
+	}
+	n.forEachAncestor(func(node *Node) {
+		for _, o := range node.observers {
+			o.nodeDetached(child)
+		}
+	})
+}
+
+// forEachAncestor вызывает fn по одному разу для узла и каждого из его предков,
+// в том числе если цепочка родителей замкнута в цикл через AddChild
+func (n *Node) forEachAncestor(fn func(*Node)) {
+	if !n.hasParentCycle() {
+		for node := n; node != nil; node = node.parent {
+			fn(node)
+		}
+		return
+	}
+	visited := map[*Node]bool{}
+	for node := n; node != nil && !visited[node]; node = node.parent {
+		visited[node] = true
+		fn(node)
+	}
+}
+
+// hasParentCycle сообщает, замкнута ли цепочка родителей узла в цикл
+func (n *Node) hasParentCycle() bool {
+	slow, fast := n, n
+	for fast != nil && fast.parent != nil {
+		slow, fast = slow.parent, fast.parent.parent
+		if slow == fast {
+			return true
+		}
+	}
+	return false
+}
+
+// TreeIndex индексирует узлы дерева по ID, системному имени и типу.
+// Индекс подписывается на корень и обновляется при изменении структуры через
+// AddChild, InsertChildAt, RemoveChild, Detach, MoveTo и ReplaceWith.
+// Изменения самих значений (например, переименование) индекс не отслеживает —
+// для них используйте Reindex. Узел, добавленный через AddChild сразу к нескольким
+// родителям, остается в индексе, пока не отсоединен от всех них.
+type TreeIndex struct {
+	mu        sync.RWMutex
+	root      *Node
+	byID      map[uuid.UUID]*Node
+	bySysName map[string][]*Node
+	byType    map[string][]*Node
+	keys      map[*Node]indexKey
+	closed    bool
+}
+
+// indexKey хранит ключи, под которыми узел был проиндексирован
+type indexKey struct {
+	id      uuid.UUID
+	hasID   bool
+	sysName string
+	typ     string
+	// refs — сколько раз узел входит в дерево (через AddChild он может быть у нескольких родителей)
+	refs int
+}
+
+// NewTreeIndex строит индекс по дереву с корнем root
+func NewTreeIndex(root *Node) *TreeIndex {
+	idx := &TreeIndex{root: root}
+	idx.build()
+	root.observers = append(root.observers, idx)
+	return idx
+}
+
+// Close отписывает индекс от дерева. После закрытия индекс не обновляется
+func (idx *TreeIndex) Close() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.closed {
+		return
+	}
+	idx.unwatchAll()
+	idx.closed = true
+	for i, o := range idx.root.observers {
+		if o == idx {
+			idx.root.observers = append(idx.root.observers[:i], idx.root.observers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Rebuild перестраивает индекс целиком
+func (idx *TreeIndex) Rebuild() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.build()
+}
+
+// Reindex обновляет ключи узла после изменения его значения
+func (idx *TreeIndex) Reindex(node *Node) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	key, ok := idx.keys[node]
+	if !ok {
+		return
+	}
+	idx.unindex(node)
+	idx.add(node)
+	updated := idx.keys[node]
+	updated.refs = key.refs
+	idx.keys[node] = updated
+}
+
+// ByID возвращает узел по ID OrgNode или EmployeeNode
+func (idx *TreeIndex) ByID(id uuid.UUID) *Node {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.byID[id]
+}
+
+// BySysName возвращает узлы OrgNode с данным системным именем
+func (idx *TreeIndex) BySysName(sysName string) []*Node {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return append([]*Node(nil), idx.bySysName[sysName]...)
+}
+
+// ByType возвращает узлы, тип которых имеет данное системное имя
+func (idx *TreeIndex) ByType(sysName string) []*Node {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return append([]*Node(nil), idx.byType[sysName]...)
+}
+
+// ParentOf возвращает родителя узла с данным ID или nil,
+// если узел не найден или является корнем индекса
+func (idx *TreeIndex) ParentOf(id uuid.UUID) *Node {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	node := idx.byID[id]
+	if node == nil || node == idx.root {
+		return nil
+	}
+	return node.Parent()
+}
+
+// Len возвращает количество проиндексированных узлов
+func (idx *TreeIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.keys)
+}
+
+func (idx *TreeIndex) nodeAttached(child *Node) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	it := NewPreOrderIterator(child)
+	for node := it.Next(); node != nil; node = it.Next() {
+		idx.add(node)
+	}
+}
+
+func (idx *TreeIndex) nodeDetached(child *Node) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	it := NewPreOrderIterator(child)
+	for node := it.Next(); node != nil; node = it.Next() {
+		idx.remove(node)
+	}
+}
+
+// build заполняет индекс обходом дерева в прямом порядке
+func (idx *TreeIndex) build() {
+	idx.unwatchAll()
+	idx.byID = make(map[uuid.UUID]*Node)
+	idx.bySysName = make(map[string][]*Node)
+	idx.byType = make(map[string][]*Node)
+	idx.keys = make(map[*Node]indexKey)
+
+	it := NewPreOrderIterator(idx.root)
+	for node := it.Next(); node != nil; node = it.Next() {
+		idx.add(node)
+	}
+}
+
+// add добавляет узел в индекс или увеличивает число его вхождений
+func (idx *TreeIndex) add(node *Node) {
+	if key, ok := idx.keys[node]; ok {
+		key.refs++
+		idx.keys[node] = key
+		return
+	}
+
+	key := indexKey{sysName: valueSysName(node.Value), typ: typeSysName(node.Value), refs: 1}
+	key.id, key.hasID = valueID(node.Value)
+	idx.keys[node] = key
+	if !idx.closed {
+		node.watched.Add(1)
+	}
+
+	if key.hasID {
+		idx.byID[key.id] = node
+	}
+	if key.sysName != "" {
+		idx.bySysName[key.sysName] = append(idx.bySysName[key.sysName], node)
+	}
+	if key.typ != "" {
+		idx.byType[key.typ] = append(idx.byType[key.typ], node)
+	}
+}
+
+// remove уменьшает число вхождений узла и удаляет его из индекса после последнего
+func (idx *TreeIndex) remove(node *Node) {
+	key, ok := idx.keys[node]
+	if !ok {
+		return
+	}
+	if key.refs > 1 {
+		key.refs--
+		idx.keys[node] = key
+		return
+	}
+	idx.unindex(node)
+}
+
+// unindex удаляет узел из индекса по ключам, с которыми он был добавлен
+func (idx *TreeIndex) unindex(node *Node) {
+	key := idx.keys[node]
+	delete(idx.keys, node)
+	if !idx.closed {
+		node.watched.Add(-1)
+	}
+
+	if key.hasID && idx.byID[key.id] == node {
+		delete(idx.byID, key.id)
+	}
+	removeFromBucket(idx.bySysName, key.sysName, node)
+	removeFromBucket(idx.byType, key.typ, node)
+}
+
+// unwatchAll снимает отметки индекса с проиндексированных узлов
+func (idx *TreeIndex) unwatchAll() {
+	if idx.closed {
+		return
+	}
+	for node := range idx.keys {
+		node.watched.Add(-1)
+	}
+}
+
+// removeFromBucket удаляет узел из списка по ключу
+func removeFromBucket(buckets map[string][]*Node, key string, node *Node) {
+	nodes := buckets[key]
+	for i, n := range nodes {
+		if n == node {
+			nodes = append(nodes[:i], nodes[i+1:]...)
+			break
+		}
+	}
+	if len(nodes) == 0 {
+		delete(buckets, key)
+	} else {
+		buckets[key] = nodes
+	}
+}
@@ -0,0 +1,250 @@
+package orgtree
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func createIndexedTree() (root *Node, ids map[string]uuid.UUID) {
+	departmentType, teamType, employeeType := createTestNodeTypes()
+	ids = map[string]uuid.UUID{}
+	newOrg := func(name, sysName string, typ *NodeType) *Node {
+		ids[sysName] = uuid.New()
+		return NewNode(&OrgNode{ID: ids[sysName], Name: name, SysName: sysName, Type: typ})
+	}
+
+	root = NewNode(nil)
+	it := newOrg("IT отдел", "it_department", departmentType)
+	hr := newOrg("HR отдел", "hr_department", departmentType)
+	dev := newOrg("Команда разработки", "dev_team", teamType)
+	qa := newOrg("Команда тестирования", "qa_team", teamType)
+	ids["ivan"] = uuid.New()
+	ivan := NewNode(&EmployeeNode{ID: ids["ivan"], Name: "Иван", Type: employeeType})
+
+	root.AddChild(it)
+	root.AddChild(hr)
+	it.AddChild(dev)
+	it.AddChild(qa)
+	dev.AddChild(ivan)
+	return root, ids
+}
+
+func TestTreeIndexLookups(t *testing.T) {
+	root, ids := createIndexedTree()
+	idx := NewTreeIndex(root)
+	defer idx.Close()
+
+	if idx.Len() != 6 {
+		t.Errorf("Expected 6 indexed nodes, got %d", idx.Len())
+	}
+
+	dev := idx.ByID(ids["dev_team"])
+	if dev == nil || valueSysName(dev.Value) != "dev_team" {
+		t.Fatalf("ByID returned wrong node: %v", dev)
+	}
+	if ivan := idx.ByID(ids["ivan"]); ivan == nil || valueName(ivan.Value) != "Иван" {
+		t.Error("ByID failed for EmployeeNode")
+	}
+	if idx.ByID(uuid.New()) != nil {
+		t.Error("ByID returned node for unknown ID")
+	}
+
+	if nodes := idx.BySysName("qa_team"); len(nodes) != 1 {
+		t.Errorf("Expected 1 node for qa_team, got %d", len(nodes))
+	}
+	if nodes := idx.ByType("team"); len(nodes) != 2 {
+		t.Errorf("Expected 2 teams, got %d", len(nodes))
+	}
+	if nodes := idx.ByType("employee"); len(nodes) != 1 {
+		t.Errorf("Expected 1 employee, got %d", len(nodes))
+	}
+
+	if parent := idx.ParentOf(ids["ivan"]); parent != dev {
+		t.Errorf("ParentOf returned wrong node: %v", parent)
+	}
+	if parent := idx.ParentOf(ids["it_department"]); parent != root {
+		t.Error("ParentOf must return wrapper root for top-level nodes")
+	}
+}
+
+func TestTreeIndexFollowsMutations(t *testing.T) {
+	root, ids := createIndexedTree()
+	idx := NewTreeIndex(root)
+	defer idx.Close()
+
+	it := idx.ByID(ids["it_department"])
+	hr := idx.ByID(ids["hr_department"])
+	dev := idx.ByID(ids["dev_team"])
+
+	// Перенос отражается в ParentOf
+	if err := dev.MoveTo(hr, -1); err != nil {
+		t.Fatalf("MoveTo failed: %v", err)
+	}
+	if idx.ParentOf(ids["dev_team"]) != hr || idx.ParentOf(ids["ivan"]) != dev {
+		t.Error("Index does not reflect moved subtree")
+	}
+
+	// Удаление убирает все поддерево
+	if err := hr.RemoveChild(dev); err != nil {
+		t.Fatalf("RemoveChild failed: %v", err)
+	}
+	if idx.ByID(ids["dev_team"]) != nil || idx.ByID(ids["ivan"]) != nil {
+		t.Error("Removed subtree is still indexed")
+	}
+	if len(idx.ByType("team")) != 1 {
+		t.Errorf("Expected 1 team after removal, got %d", len(idx.ByType("team")))
+	}
+
+	// Добавление в глубину дерева
+	newID := uuid.New()
+	it.Children[0].AddChild(NewNode(&OrgNode{ID: newID, SysName: "automation_team"}))
+	if idx.ByID(newID) == nil || len(idx.BySysName("automation_team")) != 1 {
+		t.Error("Added node is not indexed")
+	}
+
+	// Изменение значения требует Reindex
+	qa := idx.ByID(ids["qa_team"])
+	qa.Value.(*OrgNode).SysName = "testing_team"
+	idx.Reindex(qa)
+	if len(idx.BySysName("qa_team")) != 0 || len(idx.BySysName("testing_team")) != 1 {
+		t.Error("Reindex did not update sysname key")
+	}
+
+	// После Close индекс больше не обновляется
+	idx.Close()
+	hr.AddChild(NewNode(&OrgNode{ID: uuid.New(), SysName: "late_team"}))
+	if len(idx.BySysName("late_team")) != 0 {
+		t.Error("Closed index must not follow mutations")
+	}
+	idx.Rebuild()
+	if len(idx.BySysName("late_team")) != 1 {
+		t.Error("Rebuild did not pick up new node")
+	}
+}
+
+func TestTreeIndexOnSubtree(t *testing.T) {
+	root, ids := createIndexedTree()
+	it := root.Children[0]
+	idx := NewTreeIndex(it)
+	defer idx.Close()
+
+	if idx.ByID(ids["hr_department"]) != nil {
+		t.Error("Subtree index must not contain nodes outside the subtree")
+	}
+
+	// Узел, перенесенный из поддерева, удаляется из индекса
+	dev := idx.ByID(ids["dev_team"])
+	if err := dev.MoveTo(root.Children[1], 0); err != nil {
+		t.Fatalf("MoveTo failed: %v", err)
+	}
+	if idx.ByID(ids["dev_team"]) != nil {
+		t.Error("Node moved out of subtree is still indexed")
+	}
+	if idx.ParentOf(ids["it_department"]) != nil {
+		t.Error("ParentOf must return nil for index root")
+	}
+}
+
+func TestTreeIndexToleratesParentCycles(t *testing.T) {
+	root, _ := createIndexedTree()
+	idx := NewTreeIndex(root)
+	defer idx.Close()
+
+	// AddChild не проверяет циклы; уведомления все равно должны завершаться
+	x := NewNode("x")
+	y := NewNode("y")
+	x.AddChild(y)
+	y.AddChild(x)
+	y.AddChild(NewNode("z"))
+	NewNode("w").AddChild(x)
+}
+
+func TestTreeIndexWatchesOnlyIndexedTree(t *testing.T) {
+	root, ids := createIndexedTree()
+	other := NewNode("other")
+	idx := NewTreeIndex(other)
+
+	// Открытый индекс не отмечает узлы чужих деревьев
+	for node := range root.PreOrder() {
+		if node.watched.Load() != 0 {
+			t.Fatal("Unrelated tree must not be watched")
+		}
+	}
+	idx.Close()
+
+	idx = NewTreeIndex(root)
+	second := NewTreeIndex(root.Children[0])
+	it := idx.ByID(ids["it_department"])
+	if it.watched.Load() != 2 || root.watched.Load() != 1 {
+		t.Errorf("Expected watch counts 2 and 1, got %d and %d", it.watched.Load(), root.watched.Load())
+	}
+
+	// Присоединенные узлы отмечаются, и изменения под ними доходят до индекса
+	team := NewNode(&OrgNode{ID: uuid.New(), SysName: "new_team"})
+	it.AddChild(team)
+	team.AddChild(NewNode(&OrgNode{ID: uuid.New(), SysName: "nested_team"}))
+	if len(idx.BySysName("nested_team")) != 1 || len(second.BySysName("nested_team")) != 1 {
+		t.Error("Nested attachment must reach both indexes")
+	}
+
+	idx.Rebuild()
+	idx.Close()
+	second.Close()
+	idx.Close()
+	for node := range root.PreOrder() {
+		if node.watched.Load() != 0 {
+			t.Fatalf("Closed indexes must release all nodes, %v has %d", node.Value, node.watched.Load())
+		}
+	}
+}
+
+func TestForEachAncestorVisitsOnce(t *testing.T) {
+	x, y, z := NewNode("x"), NewNode("y"), NewNode("z")
+	x.AddChild(y)
+	y.AddChild(z)
+	z.AddChild(x)
+
+	for _, start := range []*Node{x, y, z} {
+		seen := map[*Node]int{}
+		start.forEachAncestor(func(n *Node) { seen[n]++ })
+		if len(seen) != 3 || seen[x] != 1 || seen[y] != 1 || seen[z] != 1 {
+			t.Errorf("Expected each node of the cycle once, got %v", seen)
+		}
+	}
+}
+
+func TestTreeIndexSharedChild(t *testing.T) {
+	root, ids := createIndexedTree()
+	idx := NewTreeIndex(root)
+	defer idx.Close()
+
+	hr := idx.ByID(ids["hr_department"])
+	dev := idx.ByID(ids["dev_team"])
+	ivan := idx.ByID(ids["ivan"])
+
+	// AddChild не отсоединяет узел от прежнего родителя: Иван теперь в двух местах дерева
+	hr.AddChild(ivan)
+	if err := dev.RemoveChild(ivan); err != nil {
+		t.Fatal(err)
+	}
+	if idx.ByID(ids["ivan"]) != ivan {
+		t.Error("Node still attached to another parent must stay indexed")
+	}
+	if err := hr.RemoveChild(ivan); err != nil {
+		t.Fatal(err)
+	}
+	if idx.ByID(ids["ivan"]) != nil || ivan.watched.Load() != 0 {
+		t.Error("Node detached from all parents must leave the index")
+	}
+
+	// Узел, добавленный к родителю в чужом дереве, удаляется из индекса вместе с последним местом в нем
+	qa := idx.ByID(ids["qa_team"])
+	NewNode("other").AddChild(qa)
+	if err := root.Children[0].RemoveChild(qa); err != nil {
+		t.Fatal(err)
+	}
+	if idx.ByID(ids["qa_team"]) != nil {
+		t.Error("Node removed from the indexed tree must leave the index")
+	}
+}
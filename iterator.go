@@ -6,91 +6,385 @@ type Iterator interface {
 
 // --- PreOrder Iterator ---
 
+// PreOrderIterator обходит дерево в прямом порядке.
+// Дети узла добавляются в стек при следующем вызове Next, поэтому после Next
+// можно вызвать SkipSubtree, чтобы не заходить в поддерево возвращенного узла.
 type PreOrderIterator struct {
-	stack []*Node
+	stack []depthEntry
+	path  []*Node
+	last  *Node
+	skip  bool
+}
+
+// depthEntry хранит узел вместе с его глубиной
+type depthEntry struct {
+	node  *Node
+	depth int
 }
 
 func NewPreOrderIterator(root *Node) *PreOrderIterator {
-	return &PreOrderIterator{stack: []*Node{root}}
+	return &PreOrderIterator{stack: []depthEntry{{node: root}}}
 }
 
 func (it *PreOrderIterator) Next() *Node {
+	if it.last != nil && !it.skip {
+		// Добавляем детей в стек в обратном порядке
+		depth := len(it.path)
+		for i := len(it.last.Children) - 1; i >= 0; i-- {
+			it.stack = append(it.stack, depthEntry{it.last.Children[i], depth})
+		}
+	}
+	it.last, it.skip = nil, false
+
 	if len(it.stack) == 0 {
 		return nil
 	}
 
-	node := it.stack[len(it.stack)-1]
+	e := it.stack[len(it.stack)-1]
 	it.stack = it.stack[:len(it.stack)-1]
 
-	// Добавляем детей в стек в обратном порядке
-	for i := len(node.Children) - 1; i >= 0; i-- {
-		it.stack = append(it.stack, node.Children[i])
-	}
+	it.path = append(it.path[:e.depth], e.node)
+	it.last = e.node
+	return e.node
+}
 
-	return node
+// SkipSubtree пропускает потомков последнего возвращенного узла
+func (it *PreOrderIterator) SkipSubtree() {
+	it.skip = true
+}
+
+// Stop завершает обход: следующие вызовы Next возвращают nil
+func (it *PreOrderIterator) Stop() {
+	it.stack, it.last = nil, nil
+}
+
+// Depth возвращает глубину последнего возвращенного узла
+func (it *PreOrderIterator) Depth() int {
+	return len(it.path) - 1
+}
+
+// Path возвращает путь от корня до последнего возвращенного узла.
+// Срез переиспользуется итератором и действителен до следующего вызова Next.
+func (it *PreOrderIterator) Path() []*Node {
+	return it.path
 }
 
 // --- PostOrder Iterator ---
 
+// PostOrderIterator обходит дерево в обратном порядке: дети раньше родителя.
+// Хранит только стек от корня до текущего узла.
 type PostOrderIterator struct {
-	stack    []*Node
-	visited  map[*Node]bool
-	initDone bool
-	root     *Node
+	stack []postOrderFrame
+	last  *Node
+}
+
+// postOrderFrame хранит узел и номер следующего непосещенного ребенка
+type postOrderFrame struct {
+	node *Node
+	next int
 }
 
 func NewPostOrderIterator(root *Node) *PostOrderIterator {
 	return &PostOrderIterator{
-		stack:   []*Node{},
-		visited: make(map[*Node]bool),
-		root:    root,
+		stack: []postOrderFrame{{node: root}},
 	}
 }
 
 func (it *PostOrderIterator) Next() *Node {
-	if !it.initDone {
-		it.stack = append(it.stack, it.root)
-		it.initDone = true
-	}
-
+	it.last = nil
 	for len(it.stack) > 0 {
-		node := it.stack[len(it.stack)-1]
-		if len(node.Children) == 0 || it.visited[node] {
-			it.stack = it.stack[:len(it.stack)-1]
-			return node
+		top := &it.stack[len(it.stack)-1]
+		if top.next < len(top.node.Children) {
+			child := top.node.Children[top.next]
+			top.next++
+			it.stack = append(it.stack, postOrderFrame{node: child})
+			continue
 		}
 
-		// добавляем детей в стек в обратном порядке
-		for i := len(node.Children) - 1; i >= 0; i-- {
-			it.stack = append(it.stack, node.Children[i])
-		}
-		it.visited[node] = true
+		it.last = top.node
+		it.stack = it.stack[:len(it.stack)-1]
+		return it.last
 	}
-
 	return nil
 }
 
+// Stop завершает обход: следующие вызовы Next возвращают nil
+func (it *PostOrderIterator) Stop() {
+	it.stack, it.last = nil, nil
+}
+
+// Depth возвращает глубину последнего возвращенного узла
+func (it *PostOrderIterator) Depth() int {
+	return len(it.stack)
+}
+
+// Path возвращает путь от корня до последнего возвращенного узла
+func (it *PostOrderIterator) Path() []*Node {
+	if it.last == nil {
+		return nil
+	}
+	path := make([]*Node, 0, len(it.stack)+1)
+	for _, frame := range it.stack {
+		path = append(path, frame.node)
+	}
+	return append(path, it.last)
+}
+
 // --- BFS Iterator ---
 
+// BFSIterator обходит дерево в ширину.
+// После Next можно вызвать SkipSubtree, чтобы не добавлять в очередь детей возвращенного узла.
 type BFSIterator struct {
-	queue []*Node
+	queue []depthEntry
+	last  depthEntry
+	skip  bool
 }
 
 func NewBFSIterator(root *Node) *BFSIterator {
-	return &BFSIterator{queue: []*Node{root}}
+	return &BFSIterator{queue: []depthEntry{{node: root}}, last: depthEntry{depth: -1}}
 }
 
 func (it *BFSIterator) Next() *Node {
+	if it.last.node != nil && !it.skip {
+		for _, child := range it.last.node.Children {
+			it.queue = append(it.queue, depthEntry{child, it.last.depth + 1})
+		}
+	}
+	it.last.node, it.skip = nil, false
+
 	if len(it.queue) == 0 {
 		return nil
 	}
 
-	node := it.queue[0]
+	it.last = it.queue[0]
 	it.queue = it.queue[1:]
+	return it.last.node
+}
+
+// SkipSubtree пропускает потомков последнего возвращенного узла
+func (it *BFSIterator) SkipSubtree() {
+	it.skip = true
+}
+
+// Stop завершает обход: следующие вызовы Next возвращают nil
+func (it *BFSIterator) Stop() {
+	it.queue, it.last.node = nil, nil
+}
 
-	for _, child := range node.Children {
-		it.queue = append(it.queue, child)
+// Depth возвращает глубину последнего возвращенного узла
+func (it *BFSIterator) Depth() int {
+	return it.last.depth
+}
+
+// --- LevelOrder Iterator ---
+
+// LevelOrderIterator обходит дерево по уровням.
+// NextLevel возвращает уровень целиком, Next — узлы по одному в порядке BFS.
+// Если уровень уже частично прочитан через Next, NextLevel вернет его остаток.
+type LevelOrderIterator struct {
+	level []*Node
+	pos   int
+	depth int
+}
+
+func NewLevelOrderIterator(root *Node) *LevelOrderIterator {
+	it := &LevelOrderIterator{depth: -1}
+	if root != nil {
+		it.level = []*Node{root}
 	}
+	return it
+}
 
+func (it *LevelOrderIterator) Next() *Node {
+	if it.pos >= len(it.level) {
+		return nil
+	}
+	if it.pos == 0 {
+		it.depth++
+	}
+	node := it.level[it.pos]
+	it.pos++
+	if it.pos == len(it.level) {
+		it.advance()
+	}
 	return node
 }
+
+// NextLevel возвращает следующий уровень дерева или nil, если уровни закончились
+func (it *LevelOrderIterator) NextLevel() []*Node {
+	if it.pos >= len(it.level) {
+		return nil
+	}
+	if it.pos == 0 {
+		it.depth++
+	}
+	level := it.level[it.pos:]
+	it.advance()
+	return level
+}
+
+// advance переходит к уровню, составленному из детей текущего
+func (it *LevelOrderIterator) advance() {
+	var next []*Node
+	for _, node := range it.level {
+		next = append(next, node.Children...)
+	}
+	it.level, it.pos = next, 0
+}
+
+// Stop завершает обход: следующие вызовы Next возвращают nil
+func (it *LevelOrderIterator) Stop() {
+	it.level, it.pos = nil, 0
+}
+
+// Depth возвращает глубину последнего возвращенного узла или уровня
+func (it *LevelOrderIterator) Depth() int {
+	return it.depth
+}
+
+// --- Leaves Iterator ---
+
+// LeavesIterator возвращает листья дерева в прямом порядке
+type LeavesIterator struct {
+	inner *PreOrderIterator
+}
+
+func NewLeavesIterator(root *Node) *LeavesIterator {
+	return &LeavesIterator{inner: NewPreOrderIterator(root)}
+}
+
+func (it *LeavesIterator) Next() *Node {
+	for node := it.inner.Next(); node != nil; node = it.inner.Next() {
+		if len(node.Children) == 0 {
+			return node
+		}
+	}
+	return nil
+}
+
+// Depth возвращает глубину последнего возвращенного листа
+func (it *LeavesIterator) Depth() int {
+	return it.inner.Depth()
+}
+
+// Path возвращает путь от корня до последнего возвращенного листа
+func (it *LeavesIterator) Path() []*Node {
+	return it.inner.Path()
+}
+
+// --- Ancestors Iterator ---
+
+// AncestorsIterator поднимается по родительским указателям от родителя узла до корня.
+// Сам узел не возвращается, как и в Ancestors.
+type AncestorsIterator struct {
+	current *Node
+}
+
+func NewAncestorsIterator(node *Node) *AncestorsIterator {
+	it := &AncestorsIterator{}
+	if node != nil {
+		it.current = node.parent
+	}
+	return it
+}
+
+func (it *AncestorsIterator) Next() *Node {
+	node := it.current
+	if node != nil {
+		it.current = node.parent
+	}
+	return node
+}
+
+// --- Siblings Iterator ---
+
+// SiblingsIterator возвращает соседей узла — остальных детей его родителя — в их порядке.
+// У корня соседей нет.
+type SiblingsIterator struct {
+	node     *Node
+	siblings []*Node
+	pos      int
+}
+
+func NewSiblingsIterator(node *Node) *SiblingsIterator {
+	it := &SiblingsIterator{node: node}
+	if node != nil && node.parent != nil {
+		it.siblings = node.parent.Children
+	}
+	return it
+}
+
+func (it *SiblingsIterator) Next() *Node {
+	for it.pos < len(it.siblings) {
+		sibling := it.siblings[it.pos]
+		it.pos++
+		if sibling != it.node {
+			return sibling
+		}
+	}
+	return nil
+}
+
+// --- DepthLimited Iterator ---
+
+// DepthLimitedIterator обходит дерево в прямом порядке, не спускаясь глубже maxDepth.
+// Корень имеет глубину 0.
+type DepthLimitedIterator struct {
+	*PreOrderIterator
+	maxDepth int
+}
+
+func NewDepthLimitedIterator(root *Node, maxDepth int) *DepthLimitedIterator {
+	it := &DepthLimitedIterator{PreOrderIterator: NewPreOrderIterator(root), maxDepth: maxDepth}
+	if maxDepth < 0 {
+		it.Stop()
+	}
+	return it
+}
+
+func (it *DepthLimitedIterator) Next() *Node {
+	node := it.PreOrderIterator.Next()
+	if node != nil && it.Depth() >= it.maxDepth {
+		it.SkipSubtree()
+	}
+	return node
+}
+
+// --- ReversePreOrder Iterator ---
+
+// ReversePreOrderIterator возвращает узлы в порядке, обратном прямому обходу:
+// последний узел прямого обхода первым, корень последним.
+// Это обратный обход с перебором детей справа налево, поэтому хранится только путь до текущего узла.
+type ReversePreOrderIterator struct {
+	stack []postOrderFrame
+}
+
+func NewReversePreOrderIterator(root *Node) *ReversePreOrderIterator {
+	it := &ReversePreOrderIterator{}
+	if root != nil {
+		it.stack = []postOrderFrame{{node: root, next: len(root.Children) - 1}}
+	}
+	return it
+}
+
+func (it *ReversePreOrderIterator) Next() *Node {
+	for len(it.stack) > 0 {
+		top := &it.stack[len(it.stack)-1]
+		if top.next >= 0 {
+			child := top.node.Children[top.next]
+			top.next--
+			it.stack = append(it.stack, postOrderFrame{node: child, next: len(child.Children) - 1})
+			continue
+		}
+
+		node := top.node
+		it.stack = it.stack[:len(it.stack)-1]
+		return node
+	}
+	return nil
+}
+
+// Stop завершает обход: следующие вызовы Next возвращают nil
+func (it *ReversePreOrderIterator) Stop() {
+	it.stack = nil
+}
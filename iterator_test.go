@@ -0,0 +1,109 @@
+package orgtree
+
+import "testing"
+
+// collectValues собирает значения всех узлов, возвращенных итератором
+func collectValues(it Iterator) []interface{} {
+	var values []interface{}
+	for node := it.Next(); node != nil; node = it.Next() {
+		values = append(values, node.Value)
+	}
+	return values
+}
+
+func nodeValues(nodes []*Node) []interface{} {
+	values := make([]interface{}, len(nodes))
+	for i, node := range nodes {
+		values[i] = node.Value
+	}
+	return values
+}
+
+func TestLevelOrderIterator(t *testing.T) {
+	root := createStringTree()
+
+	it := NewLevelOrderIterator(root)
+	assertSeqValues(t, nodeValues(it.NextLevel()), "root")
+	if it.Depth() != 0 {
+		t.Errorf("Expected depth 0, got %d", it.Depth())
+	}
+	assertSeqValues(t, nodeValues(it.NextLevel()), "child1", "child2")
+	assertSeqValues(t, nodeValues(it.NextLevel()), "grandchild1", "grandchild2")
+	if it.Depth() != 2 {
+		t.Errorf("Expected depth 2, got %d", it.Depth())
+	}
+	if level := it.NextLevel(); level != nil {
+		t.Errorf("Expected no more levels, got %v", nodeValues(level))
+	}
+
+	// Next и NextLevel можно чередовать
+	it = NewLevelOrderIterator(root)
+	it.Next()
+	it.Next()
+	assertSeqValues(t, nodeValues(it.NextLevel()), "child2")
+	assertSeqValues(t, collectValues(it), "grandchild1", "grandchild2")
+
+	assertSeqValues(t, collectValues(NewLevelOrderIterator(root)),
+		"root", "child1", "child2", "grandchild1", "grandchild2")
+	if NewLevelOrderIterator(nil).Next() != nil {
+		t.Error("Expected nil for empty tree")
+	}
+}
+
+func TestLeavesIterator(t *testing.T) {
+	root := createStringTree()
+	root.Children[1].AddChild(NewNode("grandchild3"))
+
+	it := NewLeavesIterator(root)
+	assertSeqValues(t, collectValues(it), "grandchild1", "grandchild2", "grandchild3")
+
+	it = NewLeavesIterator(root)
+	it.Next()
+	if it.Depth() != 2 || len(it.Path()) != 3 {
+		t.Errorf("Unexpected depth %d or path length %d", it.Depth(), len(it.Path()))
+	}
+}
+
+func TestAncestorsAndSiblingsIterators(t *testing.T) {
+	root := createStringTree()
+	child2 := root.Children[1]
+	grandchild2 := child2.Children[0]
+	root.AddChild(NewNode("child3"))
+
+	assertSeqValues(t, collectValues(NewAncestorsIterator(grandchild2)), "child2", "root")
+	assertSeqValues(t, collectValues(NewAncestorsIterator(root)))
+
+	assertSeqValues(t, collectValues(NewSiblingsIterator(child2)), "child1", "child3")
+	assertSeqValues(t, collectValues(NewSiblingsIterator(grandchild2)))
+	assertSeqValues(t, collectValues(NewSiblingsIterator(root)))
+}
+
+func TestDepthLimitedIterator(t *testing.T) {
+	root := createStringTree()
+
+	assertSeqValues(t, collectValues(NewDepthLimitedIterator(root, 0)), "root")
+	assertSeqValues(t, collectValues(NewDepthLimitedIterator(root, 1)), "root", "child1", "child2")
+	assertSeqValues(t, collectValues(NewDepthLimitedIterator(root, 5)),
+		"root", "child1", "grandchild1", "child2", "grandchild2")
+	assertSeqValues(t, collectValues(NewDepthLimitedIterator(root, -1)))
+}
+
+func TestReversePreOrderIterator(t *testing.T) {
+	root, _ := createLargeTree(3, 4)
+
+	var preOrder []*Node
+	it := NewPreOrderIterator(root)
+	for node := it.Next(); node != nil; node = it.Next() {
+		preOrder = append(preOrder, node)
+	}
+
+	reverse := NewReversePreOrderIterator(root)
+	for i := len(preOrder) - 1; i >= 0; i-- {
+		if node := reverse.Next(); node != preOrder[i] {
+			t.Fatalf("Position %d: expected %v, got %v", len(preOrder)-1-i, preOrder[i].Value, node)
+		}
+	}
+	if reverse.Next() != nil {
+		t.Error("Expected reverse iterator to be exhausted")
+	}
+}
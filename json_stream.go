@@ -0,0 +1,363 @@
+package orgtree
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidJSON возвращается, если поток не является корректным JSON-деревом
+var ErrInvalidJSON = errors.New("orgtree: некорректный JSON дерева")
+
+// WriteJSON записывает дерево в w в компактном JSON без построения промежуточных структур
+func (n *Node) WriteJSON(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if err := n.writeJSON(bw, ""); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// jsonFrame хранит узел, сериализация детей которого еще не завершена
+type jsonFrame struct {
+	node    *Node
+	next    int
+	written int
+}
+
+// writeJSON записывает дерево в формате {"children": [...], "value": ...} с явным стеком.
+// Ключи идут в алфавитном порядке, как при сериализации map. Узел, встречающийся
+// в дереве повторно (общий ребенок или цикл), записывается только в первый раз.
+func (n *Node) writeJSON(w io.Writer, indent string) error {
+	var (
+		err       error
+		stack     []jsonFrame
+		processed = map[*Node]bool{}
+	)
+	write := func(parts ...string) {
+		for _, part := range parts {
+			if err == nil {
+				_, err = io.WriteString(w, part)
+			}
+		}
+	}
+	// newline возвращает перевод строки с отступом для уровня level
+	newline := func(level int) string {
+		if indent == "" {
+			return ""
+		}
+		return "\n" + strings.Repeat(indent, level)
+	}
+	separator := ":"
+	if indent != "" {
+		separator = ": "
+	}
+
+	open := func(node *Node) {
+		level := 2 * len(stack)
+		write("{", newline(level+1), `"children"`, separator, "[")
+		stack = append(stack, jsonFrame{node: node})
+		processed[node] = true
+	}
+	// closeNode закрывает children и дописывает значение узла на вершине стека
+	closeNode := func(top *jsonFrame) {
+		level := 2 * (len(stack) - 1)
+		if top.written > 0 {
+			write(newline(level + 1))
+		}
+		value, marshalErr := json.Marshal(top.node.Value)
+		if marshalErr != nil && err == nil {
+			err = marshalErr
+		}
+		if indent != "" && len(value) > 0 {
+			var indented bytes.Buffer
+			if json.Indent(&indented, value, newline(level + 1)[1:], indent) == nil {
+				value = indented.Bytes()
+			}
+		}
+		write("],", newline(level+1), `"value"`, separator, string(value), newline(level), "}")
+	}
+
+	open(n)
+	for len(stack) > 0 && err == nil {
+		top := &stack[len(stack)-1]
+		if top.next < len(top.node.Children) {
+			child := top.node.Children[top.next]
+			top.next++
+			if processed[child] {
+				continue
+			}
+			if top.written > 0 {
+				write(",")
+			}
+			top.written++
+			write(newline(2 * len(stack)))
+			open(child)
+			continue
+		}
+
+		closeNode(top)
+		stack = stack[:len(stack)-1]
+	}
+	return err
+}
+
+// ReadJSON читает дерево из r потоково, без рекурсии по глубине дерева.
+// Структуру узлов разбирает собственный сканер, поэтому глубина дерева не ограничена
+// лимитом вложенности encoding/json; значения узлов декодируются через encoding/json.
+// Неизвестные ключи пропускаются; элементы children, не являющиеся объектами,
+// и повторный ключ children считаются ошибкой.
+func ReadJSON(r io.Reader) (*Node, error) {
+	s := &jsonScanner{r: bufio.NewReader(r)}
+	root, err := s.readTree()
+	if err != nil {
+		return nil, err
+	}
+	if c, err := s.next(); err != io.EOF {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: лишние данные после дерева: %q", ErrInvalidJSON, c)
+	}
+	return root, nil
+}
+
+// UnmarshalJSON разбирает дерево через ReadJSON, поэтому json.Unmarshal в Node
+// проставляет ссылки на родителя, как FromJSON
+func (n *Node) UnmarshalJSON(data []byte) error {
+	root, err := ReadJSON(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	n.Value = root.Value
+	n.Children = root.Children
+	for _, child := range n.Children {
+		child.parent = n
+	}
+	n.invalidateHashes()
+	return nil
+}
+
+// readFrame хранит узел, объект которого еще не закрыт
+type readFrame struct {
+	node        *Node
+	inChildren  bool
+	hasChildren bool
+	firstMember bool
+	firstChild  bool
+}
+
+// jsonScanner читает структурные символы JSON и значения узлов целиком
+type jsonScanner struct {
+	r *bufio.Reader
+}
+
+func (s *jsonScanner) readTree() (*Node, error) {
+	c, err := s.next()
+	if err != nil {
+		return nil, err
+	}
+	if c == 'n' {
+		if _, err := s.readValue(c); err != nil {
+			return nil, err
+		}
+		return NewNode(nil), nil
+	}
+	if c != '{' {
+		return nil, fmt.Errorf("%w: ожидался объект узла, получено %q", ErrInvalidJSON, c)
+	}
+
+	root := NewNode(nil)
+	stack := []readFrame{{node: root, firstMember: true}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		c, err := s.next()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		if top.inChildren {
+			if c == ']' {
+				top.inChildren = false
+				continue
+			}
+			if !top.firstChild {
+				if c != ',' {
+					return nil, fmt.Errorf("%w: ожидалась ',' в children, получено %q", ErrInvalidJSON, c)
+				}
+				if c, err = s.next(); err != nil {
+					return nil, unexpectedEOF(err)
+				}
+			}
+			top.firstChild = false
+
+			if c != '{' {
+				return nil, fmt.Errorf("%w: элемент children должен быть объектом, получено %q", ErrInvalidJSON, c)
+			}
+			child := NewNode(nil)
+			top.node.AddChild(child)
+			stack = append(stack, readFrame{node: child, firstMember: true})
+			continue
+		}
+
+		if c == '}' {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		if !top.firstMember {
+			if c != ',' {
+				return nil, fmt.Errorf("%w: ожидалась ',' между полями, получено %q", ErrInvalidJSON, c)
+			}
+			if c, err = s.next(); err != nil {
+				return nil, unexpectedEOF(err)
+			}
+		}
+		top.firstMember = false
+
+		key, err := s.readKey(c)
+		if err != nil {
+			return nil, err
+		}
+		if c, err = s.next(); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		switch {
+		case key == "children" && top.hasChildren:
+			return nil, fmt.Errorf("%w: повторный ключ children", ErrInvalidJSON)
+		case key == "children" && c == '[':
+			top.inChildren, top.firstChild, top.hasChildren = true, true, true
+		case key == "value":
+			raw, err := s.readValue(c)
+			if err != nil {
+				return nil, err
+			}
+			var value interface{}
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, err
+			}
+			top.node.Value = value
+		default:
+			if _, err := s.readValue(c); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return root, nil
+}
+
+// next возвращает следующий символ, пропуская пробелы
+func (s *jsonScanner) next() (byte, error) {
+	for {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		}
+		return c, nil
+	}
+}
+
+// readKey читает ключ объекта вместе с двоеточием; c — уже прочитанная открывающая кавычка
+func (s *jsonScanner) readKey(c byte) (string, error) {
+	if c != '"' {
+		return "", fmt.Errorf("%w: ожидался ключ, получено %q", ErrInvalidJSON, c)
+	}
+	raw, err := s.readValue(c)
+	if err != nil {
+		return "", err
+	}
+	var key string
+	if err := json.Unmarshal(raw, &key); err != nil {
+		return "", err
+	}
+	if c, err := s.next(); err != nil {
+		return "", unexpectedEOF(err)
+	} else if c != ':' {
+		return "", fmt.Errorf("%w: ожидалось ':', получено %q", ErrInvalidJSON, c)
+	}
+	return key, nil
+}
+
+// readValue читает JSON-значение целиком, начиная с уже прочитанного символа c,
+// и проверяет его корректность
+func (s *jsonScanner) readValue(c byte) ([]byte, error) {
+	raw := []byte{c}
+	var err error
+	switch c {
+	case '"':
+		raw, err = s.readString(raw)
+	case '{', '[':
+		depth := 1
+		for depth > 0 && err == nil {
+			if c, err = s.r.ReadByte(); err != nil {
+				break
+			}
+			raw = append(raw, c)
+			switch c {
+			case '"':
+				raw, err = s.readString(raw)
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+	default:
+		// Число или литерал продолжается до разделителя
+		for {
+			if c, err = s.r.ReadByte(); err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				break
+			}
+			if strings.IndexByte(",}] \t\n\r", c) >= 0 {
+				err = s.r.UnreadByte()
+				break
+			}
+			raw = append(raw, c)
+		}
+	}
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if !json.Valid(raw) {
+		return nil, fmt.Errorf("%w: некорректное значение %q", ErrInvalidJSON, raw)
+	}
+	return raw, nil
+}
+
+// readString дочитывает строку до закрывающей кавычки с учетом экранирования
+func (s *jsonScanner) readString(raw []byte) ([]byte, error) {
+	escaped := false
+	for {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		raw = append(raw, c)
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			return raw, nil
+		}
+	}
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -44,3 +44,100 @@ type Position struct {
 	Name    string    `json:"name"`
 	SysName string    `json:"sysname"`
 }
+
+// Clone возвращает глубокую копию типа узла
+func (t *NodeType) Clone() *NodeType {
+	if t == nil {
+		return nil
+	}
+	clone := *t
+	return &clone
+}
+
+// Clone возвращает глубокую копию должности
+func (p *Position) Clone() *Position {
+	if p == nil {
+		return nil
+	}
+	clone := *p
+	return &clone
+}
+
+// Clone возвращает глубокую копию узла оргструктуры вместе с должностями и типом
+func (o *OrgNode) Clone() *OrgNode {
+	if o == nil {
+		return nil
+	}
+	clone := *o
+	clone.Type = o.Type.Clone()
+	if o.Positions != nil {
+		clone.Positions = make([]*Position, len(o.Positions))
+		for i, position := range o.Positions {
+			clone.Positions[i] = position.Clone()
+		}
+	}
+	return &clone
+}
+
+// Clone возвращает глубокую копию сотрудника
+func (e *EmployeeNode) Clone() *EmployeeNode {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.Type = e.Type.Clone()
+	return &clone
+}
+
+// valueID возвращает ID узла оргструктуры или сотрудника
+func valueID(value interface{}) (uuid.UUID, bool) {
+	switch v := value.(type) {
+	case *OrgNode:
+		if v != nil {
+			return v.ID, true
+		}
+	case *EmployeeNode:
+		if v != nil {
+			return v.ID, true
+		}
+	}
+	return uuid.Nil, false
+}
+
+// valueName возвращает имя узла оргструктуры или сотрудника
+func valueName(value interface{}) string {
+	switch v := value.(type) {
+	case *OrgNode:
+		if v != nil {
+			return v.Name
+		}
+	case *EmployeeNode:
+		if v != nil {
+			return v.Name
+		}
+	}
+	return ""
+}
+
+// valueSysName возвращает системное имя узла оргструктуры
+func valueSysName(value interface{}) string {
+	if v, ok := value.(*OrgNode); ok && v != nil {
+		return v.SysName
+	}
+	return ""
+}
+
+// valueType возвращает тип узла оргструктуры или сотрудника
+func valueType(value interface{}) *NodeType {
+	switch v := value.(type) {
+	case *OrgNode:
+		if v != nil {
+			return v.Type
+		}
+	case *EmployeeNode:
+		if v != nil {
+			return v.Type
+		}
+	}
+	return nil
+}
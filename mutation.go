@@ -0,0 +1,203 @@
+package orgtree
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNilNode возвращается, если вместо узла передан nil
+	ErrNilNode = errors.New("orgtree: узел равен nil")
+	// ErrNotChild возвращается, если узел не является дочерним для данного родителя
+	ErrNotChild = errors.New("orgtree: узел не является дочерним")
+	// ErrNoParent возвращается для операций, которым нужен родитель, при вызове на корне
+	ErrNoParent = errors.New("orgtree: у узла нет родителя")
+	// ErrIndexOutOfRange возвращается при выходе индекса за границы списка детей
+	ErrIndexOutOfRange = errors.New("orgtree: индекс вне диапазона")
+	// ErrCycle возвращается, если операция сделала бы узел потомком самого себя
+	ErrCycle = errors.New("orgtree: операция создаст цикл")
+)
+
+// InsertChildAt вставляет дочерний узел в позицию index (0 <= index <= len(Children)).
+// Если у child уже есть родитель, узел сначала отсоединяется от него.
+func (n *Node) InsertChildAt(index int, child *Node) error {
+	if child == nil {
+		return ErrNilNode
+	}
+	if child.wouldContain(n) {
+		return ErrCycle
+	}
+
+	if child.parent == n {
+		current := n.indexOf(child)
+		if current >= 0 {
+			if index < 0 || index > len(n.Children)-1 {
+				return fmt.Errorf("%w: %d", ErrIndexOutOfRange, index)
+			}
+			n.removeChildAt(current)
+			n.insertChild(index, child)
+			return nil
+		}
+	}
+
+	if index < 0 || index > len(n.Children) {
+		return fmt.Errorf("%w: %d", ErrIndexOutOfRange, index)
+	}
+	child.Detach()
+	n.insertChild(index, child)
+	return nil
+}
+
+// RemoveChild удаляет дочерний узел; удаленный узел становится корнем своего поддерева
+func (n *Node) RemoveChild(child *Node) error {
+	if child == nil {
+		return ErrNilNode
+	}
+	i := n.indexOf(child)
+	if i < 0 {
+		return ErrNotChild
+	}
+	n.removeChildAt(i)
+	return nil
+}
+
+// Detach отсоединяет узел от родителя. Для корня ничего не делает
+func (n *Node) Detach() *Node {
+	if n.parent != nil {
+		if i := n.parent.indexOf(n); i >= 0 {
+			n.parent.removeChildAt(i)
+		} else {
+			n.parent = nil
+		}
+	}
+	return n
+}
+
+// MoveTo переносит узел под newParent в позицию index.
+// Индекс отсчитывается в списке детей newParent после отсоединения узла,
+// index == -1 означает добавление в конец.
+func (n *Node) MoveTo(newParent *Node, index int) error {
+	if newParent == nil {
+		return ErrNilNode
+	}
+	if index == -1 {
+		index = len(newParent.Children)
+		if n.parent == newParent && newParent.indexOf(n) >= 0 {
+			index--
+		}
+	}
+	return newParent.InsertChildAt(index, n)
+}
+
+// ReplaceWith ставит other на место узла у его родителя; сам узел отсоединяется.
+// Если other находился в другом месте дерева, он переносится.
+func (n *Node) ReplaceWith(other *Node) error {
+	if other == nil {
+		return ErrNilNode
+	}
+	if other == n {
+		return nil
+	}
+	parent := n.parent
+	if parent == nil {
+		return ErrNoParent
+	}
+	i := parent.indexOf(n)
+	if i < 0 {
+		return ErrNotChild
+	}
+	if other.wouldContain(parent) {
+		return ErrCycle
+	}
+
+	other.Detach()
+	// other мог быть соседом узла, поэтому позицию ищем заново
+	i = parent.indexOf(n)
+	parent.removeChildAt(i)
+	parent.insertChild(i, other)
+	return nil
+}
+
+// SwapChildren меняет местами детей с индексами i и j
+func (n *Node) SwapChildren(i, j int) error {
+	if i < 0 || i >= len(n.Children) {
+		return fmt.Errorf("%w: %d", ErrIndexOutOfRange, i)
+	}
+	if j < 0 || j >= len(n.Children) {
+		return fmt.Errorf("%w: %d", ErrIndexOutOfRange, j)
+	}
+	n.Children[i], n.Children[j] = n.Children[j], n.Children[i]
+	n.invalidateHashes()
+	return nil
+}
+
+// insertChild вставляет узел в список детей без проверок
+func (n *Node) insertChild(index int, child *Node) {
+	n.Children = append(n.Children, nil)
+	copy(n.Children[index+1:], n.Children[index:])
+	n.Children[index] = child
+	child.parent = n
+	n.invalidateHashes()
+	n.notifyAttached(child)
+}
+
+// removeChildAt удаляет ребенка с индексом i без проверок
+func (n *Node) removeChildAt(i int) {
+	child := n.Children[i]
+	n.notifyDetached(child)
+	copy(n.Children[i:], n.Children[i+1:])
+	n.Children[len(n.Children)-1] = nil
+	n.Children = n.Children[:len(n.Children)-1]
+	if child.parent == n {
+		child.parent = nil
+	}
+	n.invalidateHashes()
+}
+
+// indexOf возвращает индекс ребенка или -1
+func (n *Node) indexOf(child *Node) int {
+	for i, c := range n.Children {
+		if c == child {
+			return i
+		}
+	}
+	return -1
+}
+
+// isAncestorOf сообщает, является ли узел предком other или самим other
+func (n *Node) isAncestorOf(other *Node) bool {
+	for node := other; node != nil; node = node.parent {
+		if node == n {
+			return true
+		}
+	}
+	return false
+}
+
+// wouldContain сообщает, находится ли other в поддереве узла. Сначала проверяются
+// ссылки на родителя; если они не ведут к узлу, поддерево просматривается по Children,
+// поскольку у деревьев, собранных литералами &Node{Children: ...}, ссылок на родителя нет
+func (n *Node) wouldContain(other *Node) bool {
+	if n.isAncestorOf(other) {
+		return true
+	}
+	if len(n.Children) == 0 {
+		return false
+	}
+
+	visited := map[*Node]bool{n: true}
+	stack := append([]*Node(nil), n.Children...)
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node == other {
+			return true
+		}
+		if visited[node] {
+			continue
+		}
+		visited[node] = true
+		stack = append(stack, node.Children...)
+	}
+	return false
+}
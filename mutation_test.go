@@ -0,0 +1,232 @@
+package orgtree
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func childValues(n *Node) []string {
+	values := []string{}
+	for _, child := range n.Children {
+		values = append(values, child.Value.(string))
+	}
+	return values
+}
+
+func assertChildren(t *testing.T, n *Node, expected ...string) {
+	t.Helper()
+	got := childValues(n)
+	if len(got) != len(expected) {
+		t.Fatalf("Expected children %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("Expected children %v, got %v", expected, got)
+		}
+	}
+	for _, child := range n.Children {
+		if child.Parent() != n {
+			t.Errorf("Child %v has wrong parent", child.Value)
+		}
+	}
+}
+
+func createMutationTree() (root, a, b, a1, a2 *Node) {
+	root = NewNode("root")
+	a = NewNode("a")
+	b = NewNode("b")
+	a1 = NewNode("a1")
+	a2 = NewNode("a2")
+	root.AddChild(a)
+	root.AddChild(b)
+	a.AddChild(a1)
+	a.AddChild(a2)
+	return
+}
+
+func TestInsertChildAt(t *testing.T) {
+	root, a, _, a1, _ := createMutationTree()
+
+	if err := root.InsertChildAt(1, NewNode("x")); err != nil {
+		t.Fatalf("InsertChildAt failed: %v", err)
+	}
+	assertChildren(t, root, "a", "x", "b")
+
+	// Вставка узла из другого места дерева переносит его
+	if err := root.InsertChildAt(0, a1); err != nil {
+		t.Fatalf("InsertChildAt failed: %v", err)
+	}
+	assertChildren(t, root, "a1", "a", "x", "b")
+	assertChildren(t, a, "a2")
+
+	if err := root.InsertChildAt(10, NewNode("y")); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+	if err := root.InsertChildAt(0, nil); !errors.Is(err, ErrNilNode) {
+		t.Errorf("Expected ErrNilNode, got %v", err)
+	}
+	if err := a.InsertChildAt(0, root); !errors.Is(err, ErrCycle) {
+		t.Errorf("Expected ErrCycle, got %v", err)
+	}
+	if err := a.InsertChildAt(0, a); !errors.Is(err, ErrCycle) {
+		t.Errorf("Expected ErrCycle for self insertion, got %v", err)
+	}
+}
+
+func TestRemoveChildAndDetach(t *testing.T) {
+	root, a, b, a1, _ := createMutationTree()
+
+	if err := root.RemoveChild(b); err != nil {
+		t.Fatalf("RemoveChild failed: %v", err)
+	}
+	assertChildren(t, root, "a")
+	if b.Parent() != nil {
+		t.Error("Removed node still has parent")
+	}
+
+	if err := root.RemoveChild(a1); !errors.Is(err, ErrNotChild) {
+		t.Errorf("Expected ErrNotChild, got %v", err)
+	}
+
+	if a1.Detach() != a1 {
+		t.Error("Detach must return the node itself")
+	}
+	assertChildren(t, a, "a2")
+	if a1.Parent() != nil {
+		t.Error("Detached node still has parent")
+	}
+
+	// Повторное отсоединение безопасно
+	a1.Detach()
+}
+
+func TestMoveTo(t *testing.T) {
+	root, a, b, a1, a2 := createMutationTree()
+
+	if err := a1.MoveTo(b, 0); err != nil {
+		t.Fatalf("MoveTo failed: %v", err)
+	}
+	assertChildren(t, a, "a2")
+	assertChildren(t, b, "a1")
+
+	if err := a2.MoveTo(b, -1); err != nil {
+		t.Fatalf("MoveTo failed: %v", err)
+	}
+	assertChildren(t, b, "a1", "a2")
+
+	// Перемещение внутри одного родителя
+	if err := a1.MoveTo(b, -1); err != nil {
+		t.Fatalf("MoveTo failed: %v", err)
+	}
+	assertChildren(t, b, "a2", "a1")
+	if err := a1.MoveTo(b, 0); err != nil {
+		t.Fatalf("MoveTo failed: %v", err)
+	}
+	assertChildren(t, b, "a1", "a2")
+	if err := a1.MoveTo(b, 2); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	// Перенос под собственного потомка запрещен, дерево не меняется
+	if err := root.MoveTo(a1, 0); !errors.Is(err, ErrCycle) {
+		t.Errorf("Expected ErrCycle, got %v", err)
+	}
+	if err := b.MoveTo(a1, 0); !errors.Is(err, ErrCycle) {
+		t.Errorf("Expected ErrCycle, got %v", err)
+	}
+	assertChildren(t, root, "a", "b")
+	assertChildren(t, b, "a1", "a2")
+
+	if err := a.MoveTo(nil, 0); !errors.Is(err, ErrNilNode) {
+		t.Errorf("Expected ErrNilNode, got %v", err)
+	}
+}
+
+func TestReplaceWith(t *testing.T) {
+	root, a, b, a1, a2 := createMutationTree()
+
+	x := NewNode("x")
+	if err := a.ReplaceWith(x); err != nil {
+		t.Fatalf("ReplaceWith failed: %v", err)
+	}
+	assertChildren(t, root, "x", "b")
+	if a.Parent() != nil {
+		t.Error("Replaced node still has parent")
+	}
+	assertChildren(t, a, "a1", "a2")
+
+	// Замена соседом
+	if err := x.ReplaceWith(b); err != nil {
+		t.Fatalf("ReplaceWith failed: %v", err)
+	}
+	assertChildren(t, root, "b")
+
+	if err := root.ReplaceWith(x); !errors.Is(err, ErrNoParent) {
+		t.Errorf("Expected ErrNoParent, got %v", err)
+	}
+	if err := a1.ReplaceWith(a); !errors.Is(err, ErrCycle) {
+		t.Errorf("Expected ErrCycle, got %v", err)
+	}
+	if err := a2.ReplaceWith(nil); !errors.Is(err, ErrNilNode) {
+		t.Errorf("Expected ErrNilNode, got %v", err)
+	}
+	if err := a2.ReplaceWith(a2); err != nil {
+		t.Errorf("Expected no-op for self replacement, got %v", err)
+	}
+	assertChildren(t, a, "a1", "a2")
+}
+
+func TestSwapChildren(t *testing.T) {
+	root, _, _, _, _ := createMutationTree()
+
+	if err := root.SwapChildren(0, 1); err != nil {
+		t.Fatalf("SwapChildren failed: %v", err)
+	}
+	assertChildren(t, root, "b", "a")
+
+	if err := root.SwapChildren(0, 2); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+	if err := root.SwapChildren(-1, 0); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+}
+
+func TestMutationCyclesWithoutParentLinks(t *testing.T) {
+	// Дерево из литералов: ссылок на родителя нет
+	leaf := &Node{Value: "leaf"}
+	mid := &Node{Value: "mid", Children: []*Node{leaf}}
+	root := &Node{Value: "root", Children: []*Node{mid}}
+
+	if err := root.MoveTo(leaf, -1); !errors.Is(err, ErrCycle) {
+		t.Errorf("MoveTo: expected ErrCycle, got %v", err)
+	}
+	if err := leaf.InsertChildAt(0, mid); !errors.Is(err, ErrCycle) {
+		t.Errorf("InsertChildAt: expected ErrCycle, got %v", err)
+	}
+	top, slot := NewNode("top"), NewNode("slot")
+	top.AddChild(slot)
+	if err := slot.ReplaceWith(&Node{Value: "wrapper", Children: []*Node{top}}); !errors.Is(err, ErrCycle) {
+		t.Errorf("ReplaceWith: expected ErrCycle, got %v", err)
+	}
+	if len(leaf.Children) != 0 || len(mid.Children) != 1 {
+		t.Error("Rejected operations must not change the tree")
+	}
+
+	// json.Unmarshal проставляет ссылки на родителя, как FromJSON
+	var tree Node
+	if err := json.Unmarshal([]byte(`{"value": "root", "children": [{"value": "a", "children": [{"value": "b"}]}]}`), &tree); err != nil {
+		t.Fatal(err)
+	}
+	a, b := tree.Children[0], tree.Children[0].Children[0]
+	if a.Parent() != &tree || b.Parent() != a {
+		t.Fatal("Expected parent links after json.Unmarshal")
+	}
+	if err := a.MoveTo(b, -1); !errors.Is(err, ErrCycle) {
+		t.Errorf("Expected ErrCycle after json.Unmarshal, got %v", err)
+	}
+	if b.Detach(); len(a.Children) != 0 {
+		t.Error("Detach must remove the node from its unmarshalled parent")
+	}
+}
@@ -1,9 +1,17 @@
 package orgtree
 
+import "sync/atomic"
+
 // Node представляет узел в дереве
 type Node struct {
 	Value    interface{} `json:"value"`
 	Children []*Node     `json:"children"`
+
+	parent    *Node
+	observers []treeObserver
+	// watched — число открытых индексов, в дерево которых входит узел
+	watched atomic.Int32
+	hash    atomic.Pointer[hashEntry]
 }
 
 // NewNode создает новый узел
@@ -16,5 +24,48 @@ func NewNode(value interface{}) *Node {
 
 // AddChild добавляет дочерний узел
 func (n *Node) AddChild(child *Node) {
-	n.Children = append(n.Children, child)
+	n.insertChild(len(n.Children), child)
+}
+
+// Parent возвращает родительский узел или nil для корня
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Ancestors возвращает предков узла от родителя до корня
+func (n *Node) Ancestors() []*Node {
+	ancestors := []*Node{}
+	for p := n.parent; p != nil; p = p.parent {
+		ancestors = append(ancestors, p)
+	}
+	return ancestors
+}
+
+// Root возвращает корень дерева, в котором находится узел
+func (n *Node) Root() *Node {
+	root := n
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root
+}
+
+// Path возвращает путь от корня до текущего узла по ссылкам на родителей
+func (n *Node) Path() []*Node {
+	depth := n.Depth()
+	path := make([]*Node, depth+1)
+	for node := n; node != nil; node = node.parent {
+		path[depth] = node
+		depth--
+	}
+	return path
+}
+
+// Depth возвращает глубину узла относительно корня его дерева
+func (n *Node) Depth() int {
+	depth := 0
+	for p := n.parent; p != nil; p = p.parent {
+		depth++
+	}
+	return depth
 }
@@ -134,3 +134,93 @@ func TestTreeJSONSerialization(t *testing.T) {
 		}
 	}
 }
+
+func TestParentPointers(t *testing.T) {
+	root := NewNode("root")
+	child := NewNode("child")
+	grandchild := NewNode("grandchild")
+
+	root.AddChild(child)
+	child.AddChild(grandchild)
+
+	if root.Parent() != nil {
+		t.Error("Expected nil parent for root")
+	}
+	if grandchild.Parent() != child || child.Parent() != root {
+		t.Error("Parent pointers not set by AddChild")
+	}
+	if grandchild.Root() != root || root.Root() != root {
+		t.Error("Root returned wrong node")
+	}
+
+	ancestors := grandchild.Ancestors()
+	if len(ancestors) != 2 || ancestors[0] != child || ancestors[1] != root {
+		t.Errorf("Unexpected ancestors: %v", ancestors)
+	}
+	if len(root.Ancestors()) != 0 {
+		t.Error("Expected no ancestors for root")
+	}
+
+	if depth := grandchild.Depth(); depth != 2 {
+		t.Errorf("Expected depth 2, got %d", depth)
+	}
+	path := grandchild.Path()
+	if len(path) != 3 || path[0] != root || path[1] != child || path[2] != grandchild {
+		t.Errorf("Unexpected path: %v", path)
+	}
+}
+
+func TestGetPathRelativeToSubtree(t *testing.T) {
+	root := NewNode("root")
+	child := NewNode("child")
+	grandchild := NewNode("grandchild")
+	other := NewNode("other")
+
+	root.AddChild(child)
+	child.AddChild(grandchild)
+
+	path := grandchild.GetPath(child)
+	if len(path) != 2 || path[0] != child || path[1] != grandchild {
+		t.Errorf("Unexpected path relative to subtree: %v", path)
+	}
+
+	if depth, ok := grandchild.GetDepth(child); !ok || depth != 1 {
+		t.Errorf("Expected depth 1 relative to child, got %d (%v)", depth, ok)
+	}
+
+	if _, ok := grandchild.GetDepth(other); ok {
+		t.Error("Expected GetDepth to fail for unrelated root")
+	}
+	if len(grandchild.GetPath(other)) != 0 {
+		t.Error("Expected empty path for unrelated root")
+	}
+
+	// Дерево, собранное без AddChild, обрабатывается поиском в глубину
+	manual := &Node{Value: "manual", Children: []*Node{grandchild}}
+	if depth, ok := grandchild.GetDepth(manual); !ok || depth != 1 {
+		t.Errorf("Expected depth 1 in manually built tree, got %d (%v)", depth, ok)
+	}
+}
+
+func TestGetPathStaleParent(t *testing.T) {
+	root := NewNode("root")
+	child := NewNode("child")
+	grandchild := NewNode("grandchild")
+	root.AddChild(child)
+	child.AddChild(grandchild)
+
+	// Children изменен напрямую: ссылка grandchild на родителя устарела
+	child.Children = nil
+	if path := grandchild.GetPath(root); len(path) != 0 {
+		t.Errorf("Expected no path for a node removed from Children, got %d nodes", len(path))
+	}
+	if _, ok := grandchild.GetDepth(root); ok {
+		t.Error("Expected GetDepth to fail for a node no longer under root")
+	}
+
+	// Узел перенесен напрямую: путь находится поиском
+	root.Children = append(root.Children, grandchild)
+	if path := grandchild.GetPath(root); len(path) != 2 || path[0] != root {
+		t.Errorf("Expected path through the new parent, got %d nodes", len(path))
+	}
+}
@@ -1,51 +1,67 @@
 package orgtree
 
 import (
-	"crypto/sha256"
-	"encoding/json"
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
-	"hash"
+	"io"
+	"os"
 )
 
-// GetDepth возвращает глубину текущего узла
+// GetDepth возвращает глубину текущего узла относительно root
 func (n *Node) GetDepth(root *Node) (int, bool) {
-	it := NewPreOrderIterator(root)
-	for current := it.Next(); current != nil; current = it.Next() {
-		if current == n {
-			return len(current.GetPath(root)) - 1, true
-		}
+	path := n.GetPath(root)
+	if len(path) == 0 {
+		return 0, false
 	}
-	return 0, false
+	return len(path) - 1, true
 }
 
-// GetPath возвращает путь от корня до текущего узла
+// GetPath возвращает путь от корня до текущего узла.
+// Если root является предком узла, путь строится по ссылкам на родителей за O(глубина),
+// иначе (например, для деревьев, собранных без AddChild) выполняется поиск в глубину.
 func (n *Node) GetPath(root *Node) []*Node {
-	path := []*Node{}
-	var dfs func(node *Node) bool
-	dfs = func(node *Node) bool {
-		path = append(path, node)
+	if path := n.pathTo(root); path != nil {
+		return path
+	}
+
+	// Итератор хранит путь от корня до текущего узла, поэтому рекурсия не нужна
+	it := NewPreOrderIterator(root)
+	for node := it.Next(); node != nil; node = it.Next() {
 		if node == n {
-			return true
+			return append([]*Node{}, it.Path()...)
 		}
-		for _, child := range node.Children {
-			if dfs(child) {
-				return true
-			}
+	}
+	return []*Node{}
+}
+
+// pathTo возвращает путь от root до узла по ссылкам на родителей или nil,
+// если root не встречается среди предков либо ссылка на родителя устарела
+// (узел убран из Children родителя напрямую)
+func (n *Node) pathTo(root *Node) []*Node {
+	depth := 0
+	for node := n; node != root; node = node.parent {
+		if node == nil || node.parent == nil || node.parent.indexOf(node) < 0 {
+			return nil
 		}
-		path = path[:len(path)-1]
-		return false
+		depth++
 	}
-	if dfs(root) {
-		return path
+
+	path := make([]*Node, depth+1)
+	for node := n; depth >= 0; node = node.parent {
+		path[depth] = node
+		depth--
 	}
-	return []*Node{}
+	return path
 }
 
-// Find ищет первый узел с данным значением
+// Find ищет первый узел с данным значением. Несравнимые значения, например карты
+// из FromJSON, сравниваются по содержимому
 func (n *Node) Find(value interface{}) *Node {
 	it := NewPreOrderIterator(n)
 	for node := it.Next(); node != nil; node = it.Next() {
-		if node.Value == value {
+		if valuesEqual(node.Value, value) {
 			return node
 		}
 	}
@@ -68,11 +84,9 @@ func (n *Node) SubTree(value interface{}) *Node {
 	return n.Find(value) // уже дерево от нужного корня
 }
 
-// Hash возвращает хеш дерева, учитывая значения всех узлов
+// Hash возвращает Merkle-хеш дерева, учитывая значения и структуру всех узлов
 func (n *Node) Hash() []byte {
-	h := sha256.New()
-	n.hashHelper(h)
-	return h.Sum(nil)
+	return SubtreeHash(n)
 }
 
 // HashString возвращает строковое представление хеша
@@ -80,122 +94,88 @@ func (n *Node) HashString() string {
 	return fmt.Sprintf("%x", n.Hash())
 }
 
-func (n *Node) hashHelper(h hash.Hash) {
-	valBytes, _ := json.Marshal(n.Value)
-	h.Write(valBytes)
-	for _, child := range n.Children {
-		child.hashHelper(h)
-	}
-}
-
-// ToJSON сериализует дерево в JSON, гарантируя включение всех дочерних элементов
+// ToJSON сериализует дерево в JSON с отступами, гарантируя включение всех дочерних элементов.
+// Для очень глубоких деревьев отступы занимают квадратичный объем, поэтому лучше использовать WriteJSON.
 func (n *Node) ToJSON() ([]byte, error) {
-	// Создаем карту для отслеживания обработанных узлов
-	processed := make(map[*Node]bool)
-
-	// Функция для конвертации Node в map
-	var convertNode func(*Node) map[string]interface{}
-	convertNode = func(node *Node) map[string]interface{} {
-		if processed[node] {
-			return nil
-		}
-		processed[node] = true
-
-		children := make([]interface{}, 0, len(node.Children))
-		for _, child := range node.Children {
-			if childMap := convertNode(child); childMap != nil {
-				children = append(children, childMap)
-			}
-		}
-
-		return map[string]interface{}{
-			"value":    node.Value,
-			"children": children,
-		}
-	}
-
-	// Используем BFS для проверки, что все узлы будут обработаны
-	it := NewBFSIterator(n)
-	for node := it.Next(); node != nil; node = it.Next() {
-		processed[node] = false
+	var buf bytes.Buffer
+	if err := n.writeJSON(&buf, "  "); err != nil {
+		return nil, err
 	}
-
-	// Конвертируем дерево
-	treeMap := convertNode(n)
-
-	// Сериализуем в JSON
-	return json.MarshalIndent(treeMap, "", "  ")
+	return buf.Bytes(), nil
 }
 
 // FromJSON десериализует JSON в дерево
 func FromJSON(data []byte) (*Node, error) {
-	var treeMap map[string]interface{}
-	if err := json.Unmarshal(data, &treeMap); err != nil {
-		return nil, err
-	}
-
-	// Функция для конвертации map в Node
-	var convertMap func(map[string]interface{}) *Node
-	convertMap = func(m map[string]interface{}) *Node {
-		node := NewNode(m["value"])
-		if children, ok := m["children"].([]interface{}); ok {
-			for _, child := range children {
-				if childMap, ok := child.(map[string]interface{}); ok {
-					node.AddChild(convertMap(childMap))
-				}
-			}
-		}
-		return node
-	}
-
-	return convertMap(treeMap), nil
+	return ReadJSON(bytes.NewReader(data))
 }
 
 // PrintTree выводит дерево в консоль с отступами
 func (n *Node) PrintTree() {
-	n.printTreeRecursive("", true)
+	n.WriteTree(os.Stdout)
 }
 
-// printTreeRecursive рекурсивно выводит дерево с отступами
-func (n *Node) printTreeRecursive(prefix string, isLast bool) {
-	// Определяем символы для отображения структуры
-	var marker string
-	if isLast {
-		marker = "└── "
-	} else {
-		marker = "├── "
-	}
+// printFrame хранит узел для вывода вместе с его глубиной и признаком последнего ребенка
+type printFrame struct {
+	node   *Node
+	depth  int
+	isLast bool
+}
 
-	// Выводим текущий узел
-	fmt.Printf("%s%s%v\n", prefix, marker, n.Value)
+// WriteTree выводит дерево в w в том же формате, что и PrintTree
+func (n *Node) WriteTree(w io.Writer) error {
+	return n.writeTree(w, func(node *Node) string { return fmt.Sprint(node.Value) })
+}
 
-	// Определяем префикс для детей
-	childPrefix := prefix
-	if isLast {
-		childPrefix += "    "
-	} else {
-		childPrefix += "│   "
-	}
+// writeTree выводит дерево с отступами, подписывая узлы функцией label
+func (n *Node) writeTree(w io.Writer, label func(*Node) string) error {
+	bw := bufio.NewWriter(w)
+
+	// prefix — общий буфер отступов, prefixLens[d] — длина отступа для глубины d
+	var prefix []byte
+	prefixLens := []int{0}
+	stack := []printFrame{{node: n, isLast: true}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		prefix = prefix[:prefixLens[e.depth]]
+		marker, childIndent := "├── ", "│   "
+		if e.isLast {
+			marker, childIndent = "└── ", "    "
+		}
+		bw.Write(prefix)
+		fmt.Fprintf(bw, "%s%s\n", marker, label(e.node))
 
-	// Рекурсивно обходим детей
-	for i, child := range n.Children {
-		isLastChild := i == len(n.Children)-1
-		child.printTreeRecursive(childPrefix, isLastChild)
+		prefix = append(prefix, childIndent...)
+		prefixLens = append(prefixLens[:e.depth+1], len(prefix))
+		for i := len(e.node.Children) - 1; i >= 0; i-- {
+			stack = append(stack, printFrame{e.node.Children[i], e.depth + 1, i == len(e.node.Children)-1})
+		}
 	}
+	return bw.Flush()
 }
 
-// WalkTree обходит дерево рекурсивно и вызывает callback для каждого узла
+// WalkTree обходит дерево в прямом порядке и вызывает callback для каждого узла
 func (n *Node) WalkTree(callback func(*Node, int)) {
-	n.walkTreeRecursive(callback, 0)
+	n.walkTree(context.Background(), callback)
 }
 
-// walkTreeRecursive выполняет рекурсивный обход дерева
-func (n *Node) walkTreeRecursive(callback func(*Node, int), depth int) {
-	// Вызываем callback для текущего узла
-	callback(n, depth)
+// walkTree выполняет обход дерева с явным стеком, проверяя контекст перед каждым узлом
+func (n *Node) walkTree(ctx context.Context, callback func(*Node, int)) error {
+	stack := []depthEntry{{node: n}}
+	for len(stack) > 0 {
+		if err := ctxDone(ctx); err != nil {
+			return err
+		}
+
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
 
-	// Рекурсивно обходим всех детей
-	for _, child := range n.Children {
-		child.walkTreeRecursive(callback, depth+1)
+		callback(e.node, e.depth)
+
+		for i := len(e.node.Children) - 1; i >= 0; i-- {
+			stack = append(stack, depthEntry{e.node.Children[i], e.depth + 1})
+		}
 	}
+	return nil
 }
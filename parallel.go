@@ -0,0 +1,129 @@
+package orgtree
+
+import (
+	"sync"
+)
+
+// parallelSplitDepth ограничивает глубину, до которой поддеревья раздаются воркерам.
+const parallelSplitDepth = 64
+
+// workerPool ограничивает число одновременно работающих горутин
+type workerPool struct {
+	sem chan struct{}
+	wg  sync.WaitGroup
+}
+
+// newWorkerPool создает пул; текущая горутина считается одним из воркеров
+func newWorkerPool(workers int) *workerPool {
+	return &workerPool{sem: make(chan struct{}, workers-1)}
+}
+
+// tryGo запускает task в отдельной горутине, если есть свободный воркер
+func (p *workerPool) tryGo(task func()) bool {
+	select {
+	case p.sem <- struct{}{}:
+	default:
+		return false
+	}
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.sem
+			p.wg.Done()
+		}()
+		task()
+	}()
+	return true
+}
+
+// ParallelWalk обходит дерево, вызывая fn(узел, глубина) не более чем из workers горутин.
+// Каждый узел посещается ровно один раз с той же глубиной, что и в WalkTree,
+// но порядок вызовов не определен, поэтому fn должна быть безопасной для конкурентного вызова.
+func ParallelWalk(root *Node, workers int, fn func(*Node, int)) {
+	if workers <= 1 {
+		root.WalkTree(fn)
+		return
+	}
+
+	p := newWorkerPool(workers)
+	var walk func(node *Node, depth int)
+	walk = func(node *Node, depth int) {
+		stack := []depthEntry{{node, depth}}
+		for len(stack) > 0 {
+			e := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			fn(e.node, e.depth)
+
+			for i := len(e.node.Children) - 1; i >= 0; i-- {
+				child := depthEntry{e.node.Children[i], e.depth + 1}
+				// Листья дешевле обработать на месте, чем отдавать воркеру
+				if len(child.node.Children) > 0 && p.tryGo(func() { walk(child.node, child.depth) }) {
+					continue
+				}
+				stack = append(stack, child)
+			}
+		}
+	}
+
+	walk(root, 0)
+	p.wg.Wait()
+}
+
+// ParallelFilterSubtree фильтрует дерево как FilterSubtree, распределяя поддеревья
+// между не более чем workers горутинами. Результат совпадает с FilterSubtree,
+// включая порядок детей. Предикат должен быть безопасным для конкурентного вызова.
+func ParallelFilterSubtree(root *Node, predicate func(interface{}) bool, workers int) *Node {
+	if workers <= 1 {
+		return root.FilterSubtree(predicate)
+	}
+
+	p := newWorkerPool(workers)
+	var filter func(node *Node, depth int) *Node
+	filter = func(node *Node, depth int) *Node {
+		if depth >= parallelSplitDepth {
+			return node.FilterSubtree(predicate)
+		}
+
+		results := make([]*Node, len(node.Children))
+		var wg sync.WaitGroup
+		for i, child := range node.Children {
+			if len(child.Children) > 0 {
+				wg.Add(1)
+				if p.tryGo(func() {
+					defer wg.Done()
+					results[i] = filter(child, depth+1)
+				}) {
+					continue
+				}
+				wg.Done()
+			}
+			results[i] = filter(child, depth+1)
+		}
+		wg.Wait()
+
+		var filtered *Node
+		if predicate(node.Value) || hasNonNil(results) {
+			filtered = NewNode(node.Value)
+			for _, child := range results {
+				if child != nil {
+					filtered.AddChild(child)
+				}
+			}
+		}
+		return filtered
+	}
+
+	result := filter(root, 0)
+	p.wg.Wait()
+	return result
+}
+
+func hasNonNil(nodes []*Node) bool {
+	for _, node := range nodes {
+		if node != nil {
+			return true
+		}
+	}
+	return false
+}
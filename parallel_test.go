@@ -0,0 +1,85 @@
+package orgtree
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestParallelWalk(t *testing.T) {
+	root, total := createLargeTree(8, 4)
+
+	expected := map[*Node]int{}
+	root.WalkTree(func(n *Node, depth int) { expected[n] = depth })
+
+	for _, workers := range []int{1, 2, 8} {
+		var mu sync.Mutex
+		visited := map[*Node]int{}
+		calls := 0
+		ParallelWalk(root, workers, func(n *Node, depth int) {
+			mu.Lock()
+			defer mu.Unlock()
+			visited[n] = depth
+			calls++
+		})
+
+		if calls != total || len(visited) != total {
+			t.Errorf("workers=%d: expected %d visits, got %d calls for %d nodes", workers, total, calls, len(visited))
+		}
+		for node, depth := range expected {
+			if visited[node] != depth {
+				t.Errorf("workers=%d: node %v visited at depth %d, expected %d", workers, node.Value, visited[node], depth)
+				break
+			}
+		}
+	}
+}
+
+func TestParallelFilterSubtree(t *testing.T) {
+	root, _ := createLargeTree(8, 4)
+
+	predicates := map[string]func(interface{}) bool{
+		"sparse": func(v interface{}) bool { return v.(int)%97 == 0 },
+		"dense":  func(v interface{}) bool { return v.(int)%2 == 0 },
+		"none":   func(v interface{}) bool { return false },
+		"all":    func(v interface{}) bool { return true },
+	}
+
+	for name, predicate := range predicates {
+		sequential := root.FilterSubtree(predicate)
+		for _, workers := range []int{1, 2, 8} {
+			parallel := ParallelFilterSubtree(root, predicate, workers)
+			if (sequential == nil) != (parallel == nil) {
+				t.Fatalf("%s, workers=%d: nil mismatch", name, workers)
+			}
+			if sequential == nil {
+				continue
+			}
+			// Хеш учитывает значения и порядок детей
+			if sequential.HashString() != parallel.HashString() {
+				t.Errorf("%s, workers=%d: result differs from FilterSubtree", name, workers)
+			}
+			for _, child := range parallel.Children {
+				if child.Parent() != parallel {
+					t.Errorf("%s, workers=%d: parent pointers are not set", name, workers)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestParallelFilterDeepTree(t *testing.T) {
+	root := NewNode(0)
+	current := root
+	for i := 1; i < parallelSplitDepth*3; i++ {
+		next := NewNode(i)
+		current.AddChild(next)
+		current.AddChild(NewNode(-i))
+		current = next
+	}
+
+	predicate := func(v interface{}) bool { return v.(int) < 0 && v.(int)%5 == 0 }
+	if ParallelFilterSubtree(root, predicate, 4).HashString() != root.FilterSubtree(predicate).HashString() {
+		t.Error("Parallel filter differs from sequential on a deep tree")
+	}
+}
@@ -0,0 +1,630 @@
+package orgtree
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNodeNotFound возвращается, если узел с указанным ID отсутствует
+	ErrNodeNotFound = errors.New("orgtree: узел не найден")
+	// ErrNodeExists возвращается при добавлении узла с уже существующим ID
+	ErrNodeExists = errors.New("orgtree: узел уже существует")
+	// ErrUnknownField возвращается при обновлении неизвестного поля
+	ErrUnknownField = errors.New("orgtree: неизвестное поле")
+	// ErrInvalidOperation возвращается для некорректно заполненной операции
+	ErrInvalidOperation = errors.New("orgtree: некорректная операция")
+)
+
+// OpKind описывает вид операции изменения
+type OpKind string
+
+const (
+	OpAdd          OpKind = "add"
+	OpRemove       OpKind = "remove"
+	OpMove         OpKind = "move"
+	OpUpdate       OpKind = "update"
+	OpSetPositions OpKind = "set_positions"
+)
+
+// Поля, которые можно изменить операцией OpUpdate
+const (
+	FieldName    = "name"
+	FieldSysName = "sysname"
+	FieldType    = "type"
+)
+
+// Operation описывает одну операцию изменения дерева.
+// Parent == uuid.Nil обозначает корень дерева. Index == nil (поле index не указано в JSON)
+// или -1 — добавление в конец.
+type Operation struct {
+	Op        OpKind        `json:"op"`
+	ID        uuid.UUID     `json:"id"`
+	Parent    uuid.UUID     `json:"parent"`
+	Index     *int          `json:"index,omitempty"`
+	Org       *OrgNode      `json:"org,omitempty"`
+	Employee  *EmployeeNode `json:"employee,omitempty"`
+	Field     string        `json:"field,omitempty"`
+	Value     string        `json:"value,omitempty"`
+	Type      *NodeType     `json:"type,omitempty"`
+	Positions []*Position   `json:"positions,omitempty"`
+}
+
+// AddNodeOp создает операцию добавления OrgNode или EmployeeNode под parent
+func AddNodeOp(parent uuid.UUID, index int, value interface{}) Operation {
+	op := Operation{Op: OpAdd, Parent: parent, Index: indexRef(index)}
+	switch v := value.(type) {
+	case *OrgNode:
+		op.ID, op.Org = v.ID, v
+	case *EmployeeNode:
+		op.ID, op.Employee = v.ID, v
+	}
+	return op
+}
+
+// RemoveNodeOp создает операцию удаления узла вместе с поддеревом
+func RemoveNodeOp(id uuid.UUID) Operation {
+	return Operation{Op: OpRemove, ID: id}
+}
+
+// MoveNodeOp создает операцию переноса узла под parent
+func MoveNodeOp(id, parent uuid.UUID, index int) Operation {
+	return Operation{Op: OpMove, ID: id, Parent: parent, Index: indexRef(index)}
+}
+
+// UpdateFieldOp создает операцию изменения строкового поля (FieldName или FieldSysName)
+func UpdateFieldOp(id uuid.UUID, field, value string) Operation {
+	return Operation{Op: OpUpdate, ID: id, Field: field, Value: value}
+}
+
+// UpdateTypeOp создает операцию изменения типа узла
+func UpdateTypeOp(id uuid.UUID, nodeType *NodeType) Operation {
+	return Operation{Op: OpUpdate, ID: id, Field: FieldType, Type: nodeType}
+}
+
+// SetPositionsOp создает операцию замены должностей узла
+func SetPositionsOp(id uuid.UUID, positions []*Position) Operation {
+	return Operation{Op: OpSetPositions, ID: id, Positions: positions}
+}
+
+// indexRef возвращает индекс для Operation.Index; -1 (в конец) хранится как nil
+func indexRef(index int) *int {
+	if index == -1 {
+		return nil
+	}
+	return &index
+}
+
+// index возвращает индекс операции, -1 — добавление в конец
+func (op Operation) index() int {
+	if op.Index == nil {
+		return -1
+	}
+	return *op.Index
+}
+
+// value возвращает копию добавляемого значения
+func (op Operation) value() interface{} {
+	if op.Org != nil {
+		return op.Org.Clone()
+	}
+	if op.Employee != nil {
+		return op.Employee.Clone()
+	}
+	return nil
+}
+
+// PatchError описывает операцию, которую не удалось применить
+type PatchError struct {
+	Index int
+	Op    Operation
+	Err   error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("orgtree: операция %d (%s %s): %v", e.Index, e.Op.Op, e.Op.ID, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// Patch представляет набор операций, применяемых к дереву атомарно
+type Patch struct {
+	Operations []Operation `json:"operations"`
+}
+
+// NewPatch создает набор изменений из операций
+func NewPatch(ops ...Operation) *Patch {
+	return &Patch{Operations: append([]Operation{}, ops...)}
+}
+
+// Append добавляет операции в конец набора
+func (p *Patch) Append(ops ...Operation) *Patch {
+	p.Operations = append(p.Operations, ops...)
+	return p
+}
+
+// ToJSON сериализует набор изменений в JSON
+func (p *Patch) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// PatchFromJSON десериализует набор изменений из JSON
+func PatchFromJSON(data []byte) (*Patch, error) {
+	var p Patch
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate проверяет, что набор можно применить к дереву, не изменяя его
+func (p *Patch) Validate(root *Node) error {
+	return p.applyToTree(root.Clone(), nil)
+}
+
+// Apply применяет набор к дереву. Сначала все операции проверяются на копии дерева,
+// поэтому при ошибке исходное дерево не изменяется.
+func (p *Patch) Apply(root *Node) error {
+	if err := p.Validate(root); err != nil {
+		return err
+	}
+	return p.applyToTree(root, nil)
+}
+
+// Invert возвращает набор, отменяющий действие p на дереве root.
+// Дерево root не изменяется. Для построителя используйте InvertForBuilder.
+func (p *Patch) Invert(root *Node) (*Patch, error) {
+	inverse := []Operation{}
+	if err := p.applyToTree(root.Clone(), &inverse); err != nil {
+		return nil, err
+	}
+
+	// Обратные операции применяются в обратном порядке
+	reversed := make([]Operation, 0, len(inverse))
+	for i := len(inverse) - 1; i >= 0; i-- {
+		reversed = append(reversed, inverse[i])
+	}
+	return &Patch{Operations: reversed}, nil
+}
+
+// InvertForBuilder возвращает набор, отменяющий действие p на построителе tb.
+// Построитель не изменяется. Обратные операции вычисляются по дереву из BuildTree,
+// поэтому удаленный корневой узел восстанавливается последним среди корней.
+func (p *Patch) InvertForBuilder(tb *TreeBuilder) (*Patch, error) {
+	if err := p.applyToBuilder(tb.clone()); err != nil {
+		return nil, err
+	}
+	return p.Invert(tb.BuildTree())
+}
+
+// applyToTree применяет операции к дереву. Если inverse не nil,
+// в него записываются обратные операции (в прямом порядке).
+func (p *Patch) applyToTree(root *Node, inverse *[]Operation) error {
+	idx := NewTreeIndex(root)
+	defer idx.Close()
+
+	for i, op := range p.Operations {
+		var undo []Operation
+		var err error
+		if inverse != nil {
+			undo, err = invertTreeOp(root, idx, op)
+		}
+		if err == nil {
+			err = applyTreeOp(root, idx, op)
+		}
+		if err != nil {
+			return &PatchError{Index: i, Op: op, Err: err}
+		}
+		if inverse != nil {
+			// Обратные операции одной операции должны выполняться в своем порядке,
+			// поэтому при развороте общего списка они разворачиваются заранее
+			for j := len(undo) - 1; j >= 0; j-- {
+				*inverse = append(*inverse, undo[j])
+			}
+		}
+	}
+	return nil
+}
+
+// lookup возвращает узел по ID; uuid.Nil обозначает корень
+func lookup(root *Node, idx *TreeIndex, id uuid.UUID) (*Node, error) {
+	if id == uuid.Nil {
+		return root, nil
+	}
+	if node := idx.ByID(id); node != nil {
+		return node, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, id)
+}
+
+// lookupChild возвращает существующий узел, не являющийся корнем
+func lookupChild(root *Node, idx *TreeIndex, id uuid.UUID) (*Node, error) {
+	if id == uuid.Nil {
+		return nil, fmt.Errorf("%w: не указан ID узла", ErrInvalidOperation)
+	}
+	node, err := lookup(root, idx, id)
+	if err != nil {
+		return nil, err
+	}
+	if node == root {
+		return nil, fmt.Errorf("%w: операция над корнем", ErrInvalidOperation)
+	}
+	return node, nil
+}
+
+// insertIndex приводит индекс операции к индексу для InsertChildAt
+func insertIndex(parent *Node, index int) int {
+	if index == -1 {
+		return len(parent.Children)
+	}
+	return index
+}
+
+func applyTreeOp(root *Node, idx *TreeIndex, op Operation) error {
+	switch op.Op {
+	case OpAdd:
+		value := op.value()
+		id, ok := valueID(value)
+		if !ok || id != op.ID || id == uuid.Nil {
+			return fmt.Errorf("%w: добавляемый узел должен иметь ID", ErrInvalidOperation)
+		}
+		if idx.ByID(id) != nil {
+			return fmt.Errorf("%w: %s", ErrNodeExists, id)
+		}
+		parent, err := lookup(root, idx, op.Parent)
+		if err != nil {
+			return err
+		}
+		return parent.InsertChildAt(insertIndex(parent, op.index()), NewNode(value))
+
+	case OpRemove:
+		node, err := lookupChild(root, idx, op.ID)
+		if err != nil {
+			return err
+		}
+		node.Detach()
+		return nil
+
+	case OpMove:
+		node, err := lookupChild(root, idx, op.ID)
+		if err != nil {
+			return err
+		}
+		parent, err := lookup(root, idx, op.Parent)
+		if err != nil {
+			return err
+		}
+		return node.MoveTo(parent, op.index())
+
+	case OpUpdate:
+		node, err := lookupChild(root, idx, op.ID)
+		if err != nil {
+			return err
+		}
+		if err := updateValue(node.Value, op); err != nil {
+			return err
+		}
+		node.InvalidateHash()
+		idx.Reindex(node)
+		return nil
+
+	case OpSetPositions:
+		node, err := lookupChild(root, idx, op.ID)
+		if err != nil {
+			return err
+		}
+		if err := setPositions(node.Value, op.Positions); err != nil {
+			return err
+		}
+		node.InvalidateHash()
+		return nil
+
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidOperation, op.Op)
+	}
+}
+
+// invertTreeOp возвращает операции, отменяющие op, по текущему состоянию дерева
+func invertTreeOp(root *Node, idx *TreeIndex, op Operation) ([]Operation, error) {
+	switch op.Op {
+	case OpAdd:
+		return []Operation{RemoveNodeOp(op.ID)}, nil
+
+	case OpRemove:
+		node, err := lookupChild(root, idx, op.ID)
+		if err != nil {
+			return nil, err
+		}
+		// Удаленное поддерево восстанавливается добавлением узлов в прямом порядке
+		ops := []Operation{}
+		it := NewPreOrderIterator(node)
+		for current := it.Next(); current != nil; current = it.Next() {
+			if _, ok := valueID(current.Value); !ok {
+				return nil, fmt.Errorf("%w: поддерево содержит узел без ID", ErrInvalidOperation)
+			}
+			parentID, err := parentIDOf(root, current)
+			if err != nil {
+				return nil, err
+			}
+			index := -1
+			if current == node {
+				index = node.parent.indexOf(node)
+			}
+			ops = append(ops, AddNodeOp(parentID, index, CopyValue(current.Value)))
+		}
+		return ops, nil
+
+	case OpMove:
+		node, err := lookupChild(root, idx, op.ID)
+		if err != nil {
+			return nil, err
+		}
+		parentID, err := parentIDOf(root, node)
+		if err != nil {
+			return nil, err
+		}
+		return []Operation{MoveNodeOp(op.ID, parentID, node.parent.indexOf(node))}, nil
+
+	case OpUpdate:
+		node, err := lookupChild(root, idx, op.ID)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Field {
+		case FieldName:
+			return []Operation{UpdateFieldOp(op.ID, FieldName, valueName(node.Value))}, nil
+		case FieldSysName:
+			return []Operation{UpdateFieldOp(op.ID, FieldSysName, valueSysName(node.Value))}, nil
+		case FieldType:
+			return []Operation{UpdateTypeOp(op.ID, valueType(node.Value).Clone())}, nil
+		}
+		return nil, fmt.Errorf("%w: %q", ErrUnknownField, op.Field)
+
+	case OpSetPositions:
+		node, err := lookupChild(root, idx, op.ID)
+		if err != nil {
+			return nil, err
+		}
+		positions := []*Position{}
+		for _, position := range valuePositions(node.Value) {
+			positions = append(positions, position.Clone())
+		}
+		return []Operation{SetPositionsOp(op.ID, positions)}, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidOperation, op.Op)
+	}
+}
+
+// parentIDOf возвращает ID родителя для операций; корень обозначается uuid.Nil
+func parentIDOf(root, node *Node) (uuid.UUID, error) {
+	if node.parent == root {
+		return uuid.Nil, nil
+	}
+	if id, ok := valueID(node.parent.Value); ok {
+		return id, nil
+	}
+	return uuid.Nil, fmt.Errorf("%w: родитель узла не имеет ID", ErrInvalidOperation)
+}
+
+// updateValue изменяет поле значения узла
+func updateValue(value interface{}, op Operation) error {
+	switch v := value.(type) {
+	case *OrgNode:
+		switch op.Field {
+		case FieldName:
+			v.Name = op.Value
+		case FieldSysName:
+			v.SysName = op.Value
+		case FieldType:
+			v.Type = op.Type.Clone()
+		default:
+			return fmt.Errorf("%w: %q", ErrUnknownField, op.Field)
+		}
+	case *EmployeeNode:
+		switch op.Field {
+		case FieldName:
+			v.Name = op.Value
+		case FieldType:
+			v.Type = op.Type.Clone()
+		default:
+			return fmt.Errorf("%w: %q", ErrUnknownField, op.Field)
+		}
+	default:
+		return fmt.Errorf("%w: значение %T не поддерживается", ErrInvalidOperation, value)
+	}
+	return nil
+}
+
+// setPositions заменяет должности узла оргструктуры копиями positions
+func setPositions(value interface{}, positions []*Position) error {
+	orgNode, ok := value.(*OrgNode)
+	if !ok {
+		return fmt.Errorf("%w: должности есть только у OrgNode", ErrInvalidOperation)
+	}
+	copied := make([]*Position, len(positions))
+	for i, position := range positions {
+		copied[i] = position.Clone()
+	}
+	orgNode.Positions = copied
+	return nil
+}
+
+// ApplyToBuilder применяет набор к данным построителя. Операции сначала проверяются
+// на копии данных, поэтому при ошибке построитель не изменяется.
+// Parent == uuid.Nil делает узел корневым.
+func (p *Patch) ApplyToBuilder(tb *TreeBuilder) error {
+	if err := p.applyToBuilder(tb.clone()); err != nil {
+		return err
+	}
+	return p.applyToBuilder(tb)
+}
+
+func (p *Patch) applyToBuilder(tb *TreeBuilder) error {
+	for i, op := range p.Operations {
+		if err := tb.applyOp(op); err != nil {
+			return &PatchError{Index: i, Op: op, Err: err}
+		}
+	}
+	return nil
+}
+
+// clone возвращает копию построителя с копиями значений
+func (tb *TreeBuilder) clone() *TreeBuilder {
+	clone := NewTreeBuilder()
+	for _, id := range tb.orderedIDs() {
+		if orgNode, ok := tb.nodes[id]; ok {
+			clone.AddNode(orgNode.Clone())
+		}
+		if employeeNode, ok := tb.employeeNodes[id]; ok {
+			clone.AddNode(employeeNode.Clone())
+		}
+	}
+	for _, edge := range tb.edges {
+		edgeCopy := *edge
+		clone.AddEdge(&edgeCopy)
+	}
+	return clone
+}
+
+// value возвращает значение узла построителя по ID
+func (tb *TreeBuilder) value(id uuid.UUID) (interface{}, bool) {
+	if employeeNode, ok := tb.employeeNodes[id]; ok {
+		return employeeNode, true
+	}
+	if orgNode, ok := tb.nodes[id]; ok {
+		return orgNode, true
+	}
+	return nil, false
+}
+
+func (tb *TreeBuilder) applyOp(op Operation) error {
+	if op.Op != OpAdd {
+		if _, ok := tb.value(op.ID); !ok {
+			return fmt.Errorf("%w: %s", ErrNodeNotFound, op.ID)
+		}
+	}
+	if (op.Op == OpAdd || op.Op == OpMove) && op.Parent != uuid.Nil {
+		if _, ok := tb.value(op.Parent); !ok {
+			return fmt.Errorf("%w: %s", ErrNodeNotFound, op.Parent)
+		}
+	}
+
+	switch op.Op {
+	case OpAdd:
+		value := op.value()
+		if id, ok := valueID(value); !ok || id != op.ID || id == uuid.Nil {
+			return fmt.Errorf("%w: добавляемый узел должен иметь ID", ErrInvalidOperation)
+		}
+		if _, ok := tb.value(op.ID); ok {
+			return fmt.Errorf("%w: %s", ErrNodeExists, op.ID)
+		}
+		if op.Parent != uuid.Nil {
+			if err := tb.insertEdge(op.Parent, op.ID, op.index()); err != nil {
+				return err
+			}
+		}
+		tb.AddNode(value)
+		return nil
+
+	case OpRemove:
+		removed := tb.subtree(op.ID)
+		edges := tb.edges[:0]
+		for _, edge := range tb.edges {
+			if !removed[edge.FromNode] && !removed[edge.ToNode] {
+				edges = append(edges, edge)
+			}
+		}
+		tb.edges = edges
+		order := tb.order[:0]
+		for _, id := range tb.order {
+			if removed[id] {
+				delete(tb.nodes, id)
+				delete(tb.employeeNodes, id)
+			} else {
+				order = append(order, id)
+			}
+		}
+		tb.order = order
+		return nil
+
+	case OpMove:
+		if tb.subtree(op.ID)[op.Parent] {
+			return ErrCycle
+		}
+		edges := tb.edges[:0]
+		for _, edge := range tb.edges {
+			if edge.ToNode != op.ID {
+				edges = append(edges, edge)
+			}
+		}
+		tb.edges = edges
+		if op.Parent == uuid.Nil {
+			return nil
+		}
+		return tb.insertEdge(op.Parent, op.ID, op.index())
+
+	case OpUpdate:
+		value, _ := tb.value(op.ID)
+		return updateValue(value, op)
+
+	case OpSetPositions:
+		value, _ := tb.value(op.ID)
+		return setPositions(value, op.Positions)
+
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidOperation, op.Op)
+	}
+}
+
+// subtree возвращает ID узла и всех его потомков по связям построителя
+func (tb *TreeBuilder) subtree(id uuid.UUID) map[uuid.UUID]bool {
+	children := make(map[uuid.UUID][]uuid.UUID)
+	for _, edge := range tb.edges {
+		children[edge.FromNode] = append(children[edge.FromNode], edge.ToNode)
+	}
+
+	result := map[uuid.UUID]bool{id: true}
+	stack := []uuid.UUID{id}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, child := range children[current] {
+			if !result[child] {
+				result[child] = true
+				stack = append(stack, child)
+			}
+		}
+	}
+	return result
+}
+
+// insertEdge вставляет связь parent → child так, чтобы child стал ребенком с номером index
+func (tb *TreeBuilder) insertEdge(parent, child uuid.UUID, index int) error {
+	edge := &Edge{FromNode: parent, ToNode: child}
+
+	positions := []int{}
+	for i, e := range tb.edges {
+		if e.FromNode == parent {
+			positions = append(positions, i)
+		}
+	}
+	if index == -1 || index == len(positions) {
+		tb.AddEdge(edge)
+		return nil
+	}
+	if index < 0 || index > len(positions) {
+		return fmt.Errorf("%w: %d", ErrIndexOutOfRange, index)
+	}
+
+	at := positions[index]
+	tb.edges = append(tb.edges, nil)
+	copy(tb.edges[at+1:], tb.edges[at:])
+	tb.edges[at] = edge
+	return nil
+}
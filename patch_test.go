@@ -0,0 +1,308 @@
+package orgtree
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func createReorgPatch(ids map[string]uuid.UUID) (*Patch, uuid.UUID) {
+	financeID := uuid.New()
+	patch := NewPatch(
+		AddNodeOp(uuid.Nil, -1, &OrgNode{ID: financeID, Name: "Финансы", SysName: "finance"}),
+		MoveNodeOp(ids["qa_team"], ids["hr_department"], 0),
+		UpdateFieldOp(ids["dev_team"], FieldName, "Команда платформы"),
+		UpdateFieldOp(ids["dev_team"], FieldSysName, "platform_team"),
+		UpdateTypeOp(ids["hr_department"], &NodeType{ID: uuid.New(), Name: "Дирекция", SysName: "directorate"}),
+		SetPositionsOp(ids["it_department"], []*Position{{ID: uuid.New(), Name: "CTO", SysName: "cto"}}),
+		RemoveNodeOp(ids["ivan"]),
+	)
+	return patch, financeID
+}
+
+func TestPatchApply(t *testing.T) {
+	root, ids := createIndexedTree()
+	patch, financeID := createReorgPatch(ids)
+
+	if err := patch.Apply(root); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	idx := NewTreeIndex(root)
+	defer idx.Close()
+
+	if idx.ParentOf(financeID) != root {
+		t.Error("Added node is not under root")
+	}
+	if parent := idx.ParentOf(ids["qa_team"]); parent == nil || valueSysName(parent.Value) != "hr_department" {
+		t.Error("Moved node has wrong parent")
+	}
+	dev := idx.ByID(ids["dev_team"]).Value.(*OrgNode)
+	if dev.Name != "Команда платформы" || dev.SysName != "platform_team" {
+		t.Errorf("Fields were not updated: %+v", dev)
+	}
+	if typeSysName(idx.ByID(ids["hr_department"]).Value) != "directorate" {
+		t.Error("Type was not updated")
+	}
+	if positions := valuePositions(idx.ByID(ids["it_department"]).Value); len(positions) != 1 || positions[0].SysName != "cto" {
+		t.Error("Positions were not set")
+	}
+	if idx.ByID(ids["ivan"]) != nil {
+		t.Error("Removed node is still in the tree")
+	}
+}
+
+func TestPatchIsAtomic(t *testing.T) {
+	root, ids := createIndexedTree()
+	before := root.HashString()
+
+	patch := NewPatch(
+		UpdateFieldOp(ids["dev_team"], FieldName, "Изменено"),
+		MoveNodeOp(ids["it_department"], ids["dev_team"], 0),
+	)
+
+	err := patch.Apply(root)
+	var patchErr *PatchError
+	if !errors.As(err, &patchErr) || patchErr.Index != 1 || !errors.Is(err, ErrCycle) {
+		t.Fatalf("Expected PatchError for operation 1 wrapping ErrCycle, got %v", err)
+	}
+	if root.HashString() != before {
+		t.Error("Failed patch modified the tree")
+	}
+}
+
+func TestPatchValidation(t *testing.T) {
+	root, ids := createIndexedTree()
+
+	tests := []struct {
+		name string
+		op   Operation
+		err  error
+	}{
+		{"unknown node", RemoveNodeOp(uuid.New()), ErrNodeNotFound},
+		{"duplicate id", AddNodeOp(ids["dev_team"], -1, &OrgNode{ID: ids["qa_team"]}), ErrNodeExists},
+		{"unknown parent", MoveNodeOp(ids["dev_team"], uuid.New(), 0), ErrNodeNotFound},
+		{"bad index", MoveNodeOp(ids["dev_team"], ids["hr_department"], 5), ErrIndexOutOfRange},
+		{"unknown field", UpdateFieldOp(ids["dev_team"], "budget", "1"), ErrUnknownField},
+		{"employee sysname", UpdateFieldOp(ids["ivan"], FieldSysName, "x"), ErrUnknownField},
+		{"employee positions", SetPositionsOp(ids["ivan"], nil), ErrInvalidOperation},
+		{"root removal", RemoveNodeOp(uuid.Nil), ErrInvalidOperation},
+		{"unknown op", Operation{Op: "rename", ID: ids["dev_team"]}, ErrInvalidOperation},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := NewPatch(test.op).Validate(root); !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestPatchInvert(t *testing.T) {
+	root, ids := createIndexedTree()
+	before := root.Clone()
+	patch, _ := createReorgPatch(ids)
+	// Удаление поддерева с потомками
+	patch.Append(RemoveNodeOp(ids["it_department"]))
+
+	inverse, err := patch.Invert(root)
+	if err != nil {
+		t.Fatalf("Invert failed: %v", err)
+	}
+	if !Diff(before, root).Empty() {
+		t.Fatal("Invert modified the tree")
+	}
+
+	if err := patch.Apply(root); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if Diff(before, root).Empty() {
+		t.Fatal("Patch had no effect")
+	}
+
+	if err := inverse.Apply(root); err != nil {
+		t.Fatalf("Applying inverse failed: %v", err)
+	}
+	if diff := Diff(before, root); !diff.Empty() {
+		t.Errorf("Rollback did not restore the tree:\n%s", diff)
+	}
+	if before.HashString() != root.HashString() {
+		t.Error("Rollback did not restore children order")
+	}
+}
+
+func TestPatchJSON(t *testing.T) {
+	root, ids := createIndexedTree()
+	patch, _ := createReorgPatch(ids)
+
+	data, err := patch.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	decoded, err := PatchFromJSON(data)
+	if err != nil {
+		t.Fatalf("PatchFromJSON failed: %v", err)
+	}
+	if len(decoded.Operations) != len(patch.Operations) {
+		t.Fatalf("Expected %d operations, got %d", len(patch.Operations), len(decoded.Operations))
+	}
+
+	expected := root.Clone()
+	if err := patch.Apply(expected); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if err := decoded.Apply(root); err != nil {
+		t.Fatalf("Apply of decoded patch failed: %v", err)
+	}
+	if diff := Diff(expected, root); !diff.Empty() {
+		t.Errorf("Decoded patch gives different result:\n%s", diff)
+	}
+}
+
+func TestPatchApplyToBuilder(t *testing.T) {
+	builder := NewTreeBuilder()
+	office := &OrgNode{ID: uuid.New(), Name: "Главный офис", SysName: "main_office"}
+	it := &OrgNode{ID: uuid.New(), Name: "IT отдел", SysName: "it_department"}
+	hr := &OrgNode{ID: uuid.New(), Name: "HR отдел", SysName: "hr_department"}
+	dev := &OrgNode{ID: uuid.New(), Name: "Команда разработки", SysName: "dev_team"}
+	for _, node := range []*OrgNode{office, it, hr, dev} {
+		builder.AddNode(node)
+	}
+	builder.AddEdge(&Edge{FromNode: office.ID, ToNode: it.ID})
+	builder.AddEdge(&Edge{FromNode: office.ID, ToNode: hr.ID})
+	builder.AddEdge(&Edge{FromNode: it.ID, ToNode: dev.ID})
+
+	qaID := uuid.New()
+	patch := NewPatch(
+		AddNodeOp(office.ID, 0, &OrgNode{ID: qaID, Name: "QA", SysName: "qa"}),
+		MoveNodeOp(dev.ID, hr.ID, -1),
+		UpdateFieldOp(hr.ID, FieldName, "Отдел кадров"),
+	)
+	if err := patch.ApplyToBuilder(builder); err != nil {
+		t.Fatalf("ApplyToBuilder failed: %v", err)
+	}
+
+	tree := builder.BuildTree()
+	officeNode := tree.Children[0]
+	if len(officeNode.Children) != 3 || valueSysName(officeNode.Children[0].Value) != "qa" {
+		t.Fatalf("Added node has wrong position: %v", orgNames(officeNode.Children))
+	}
+	hrNode := officeNode.Children[2]
+	if valueName(hrNode.Value) != "Отдел кадров" || len(hrNode.Children) != 1 || hrNode.Children[0].Value != dev {
+		t.Error("Move or update was not applied to builder")
+	}
+
+	// Ошибочный набор не меняет построитель
+	bad := NewPatch(RemoveNodeOp(it.ID), MoveNodeOp(office.ID, dev.ID, 0))
+	if err := bad.ApplyToBuilder(builder); !errors.Is(err, ErrCycle) {
+		t.Fatalf("Expected ErrCycle, got %v", err)
+	}
+	if _, ok := builder.Node(it.ID); !ok {
+		t.Error("Failed patch modified the builder")
+	}
+
+	if err := NewPatch(RemoveNodeOp(hr.ID)).ApplyToBuilder(builder); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, ok := builder.Node(dev.ID); ok {
+		t.Error("Descendants of removed node must be removed from builder")
+	}
+	for _, edge := range builder.Edges() {
+		if edge.ToNode == hr.ID || edge.FromNode == hr.ID {
+			t.Error("Edges of removed node remain in builder")
+		}
+	}
+}
+
+func TestPatchJSONIndex(t *testing.T) {
+	root, ids := createIndexedTree()
+	financeID, legalID := uuid.New(), uuid.New()
+
+	// Без поля index узел добавляется в конец, а явный индекс сохраняется
+	data := fmt.Sprintf(`{"operations": [
+		{"op": "add", "id": %[1]q, "parent": %[3]q, "org": {"id": %[1]q, "name": "Финансы", "sysname": "finance"}},
+		{"op": "add", "id": %[2]q, "parent": %[3]q, "index": 0, "org": {"id": %[2]q, "name": "Юристы", "sysname": "legal"}},
+		{"op": "move", "id": %[4]q, "parent": %[3]q}
+	]}`, financeID, legalID, uuid.Nil, ids["qa_team"])
+	patch, err := PatchFromJSON([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := patch.Apply(root); err != nil {
+		t.Fatal(err)
+	}
+	var sysNames []string
+	for _, child := range root.Children {
+		sysNames = append(sysNames, valueSysName(child.Value))
+	}
+	if strings.Join(sysNames, ",") != "legal,it_department,hr_department,finance,qa_team" {
+		t.Errorf("Unexpected order: %v", sysNames)
+	}
+
+	// Добавление в конец не записывает индекс, операции без индекса его не содержат
+	encoded, _ := NewPatch(AddNodeOp(uuid.Nil, -1, &OrgNode{ID: uuid.New()}), RemoveNodeOp(financeID)).ToJSON()
+	if strings.Contains(string(encoded), `"index"`) {
+		t.Errorf("Expected no index in JSON, got %s", encoded)
+	}
+	encoded, _ = NewPatch(MoveNodeOp(financeID, uuid.Nil, 0)).ToJSON()
+	decoded, err := PatchFromJSON(encoded)
+	if err != nil || decoded.Operations[0].Index == nil || *decoded.Operations[0].Index != 0 {
+		t.Errorf("Expected explicit zero index to survive JSON, got %s", encoded)
+	}
+}
+
+func TestPatchInvertForBuilder(t *testing.T) {
+	builder := NewTreeBuilder()
+	office := &OrgNode{ID: uuid.New(), Name: "Главный офис", SysName: "main_office"}
+	it := &OrgNode{ID: uuid.New(), Name: "IT отдел", SysName: "it_department"}
+	hr := &OrgNode{ID: uuid.New(), Name: "HR отдел", SysName: "hr_department"}
+	dev := &OrgNode{ID: uuid.New(), Name: "Команда разработки", SysName: "dev_team"}
+	ivan := &EmployeeNode{ID: uuid.New(), Name: "Иван"}
+	builder.AddNode(office)
+	builder.AddNode(it)
+	builder.AddNode(hr)
+	builder.AddNode(dev)
+	builder.AddNode(ivan)
+	builder.AddEdge(&Edge{FromNode: office.ID, ToNode: it.ID})
+	builder.AddEdge(&Edge{FromNode: office.ID, ToNode: hr.ID})
+	builder.AddEdge(&Edge{FromNode: it.ID, ToNode: dev.ID})
+	builder.AddEdge(&Edge{FromNode: dev.ID, ToNode: ivan.ID})
+	before := builder.BuildTree().Clone()
+
+	patch := NewPatch(
+		AddNodeOp(office.ID, 0, &OrgNode{ID: uuid.New(), Name: "QA", SysName: "qa"}),
+		MoveNodeOp(hr.ID, it.ID, 0),
+		UpdateFieldOp(it.ID, FieldName, "ИТ"),
+		RemoveNodeOp(dev.ID),
+	)
+	inverse, err := patch.InvertForBuilder(builder)
+	if err != nil {
+		t.Fatalf("InvertForBuilder failed: %v", err)
+	}
+	if builder.BuildTree().HashString() != before.HashString() {
+		t.Fatal("InvertForBuilder modified the builder")
+	}
+
+	if err := patch.ApplyToBuilder(builder); err != nil {
+		t.Fatal(err)
+	}
+	if err := inverse.ApplyToBuilder(builder); err != nil {
+		t.Fatalf("Applying inverse failed: %v", err)
+	}
+	after := builder.BuildTree()
+	if diff := Diff(before, after); !diff.Empty() {
+		t.Errorf("Rollback did not restore the builder:\n%s", diff)
+	}
+	if after.HashString() != before.HashString() {
+		t.Error("Rollback did not restore children order")
+	}
+
+	// Ошибка набора сообщается как при ApplyToBuilder
+	if _, err := NewPatch(MoveNodeOp(office.ID, it.ID, 0)).InvertForBuilder(builder); !errors.Is(err, ErrCycle) {
+		t.Errorf("Expected ErrCycle, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+// Package predicates содержит составные условия для фильтрации деревьев orgtree
+// по моделям OrgNode и EmployeeNode.
+package predicates
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arsants/orgtree"
+)
+
+// ErrStructural возвращается Func для условий, зависящих от положения узла в дереве
+var ErrStructural = errors.New("predicates: условие зависит от положения узла в дереве")
+
+// Predicate — условие на узел дерева с текстовым описанием для логов.
+// Подходит для FilterSubtreeBy, FilterBy и FilterSubtreeByMode; Func адаптирует
+// условия по значению к FilterSubtree и Filter. Нулевое условие ложно для всех узлов.
+type Predicate struct {
+	desc  string
+	match func(*orgtree.Node) bool
+	// structural — условие смотрит на детей или глубину узла, а не только на значение
+	structural bool
+}
+
+// New создает условие по значению узла из функции и описания
+func New(desc string, match func(*orgtree.Node) bool) Predicate {
+	return Predicate{desc: desc, match: match}
+}
+
+// NewStructural создает условие, которому нужны связи узла с деревом (дети, родители)
+func NewStructural(desc string, match func(*orgtree.Node) bool) Predicate {
+	return Predicate{desc: desc, match: match, structural: true}
+}
+
+// Match проверяет условие для узла
+func (p Predicate) Match(n *orgtree.Node) bool {
+	if p.match == nil {
+		return false
+	}
+	return p.match(n)
+}
+
+// Structural сообщает, зависит ли условие от положения узла в дереве
+func (p Predicate) Structural() bool {
+	return p.structural
+}
+
+// String возвращает описание условия, например And(IsOrgNode, TypeIs("team"))
+func (p Predicate) String() string {
+	return p.desc
+}
+
+// Func возвращает условие по значению для FilterSubtree и Filter.
+// Для структурных условий (HasChildren, DepthBetween и составных с ними) значения
+// недостаточно, и возвращается ErrStructural — используйте FilterSubtreeBy.
+func (p Predicate) Func() (func(interface{}) bool, error) {
+	if p.structural {
+		return nil, fmt.Errorf("%w: %s", ErrStructural, p.desc)
+	}
+	return func(value interface{}) bool {
+		return p.Match(orgtree.NewNode(value))
+	}, nil
+}
+
+// And истинно, если истинны все условия; без аргументов всегда истинно
+func And(predicates ...Predicate) Predicate {
+	return combine(join("And", predicates), predicates, func(n *orgtree.Node) bool {
+		for _, p := range predicates {
+			if !p.Match(n) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Or истинно, если истинно хотя бы одно условие; без аргументов всегда ложно
+func Or(predicates ...Predicate) Predicate {
+	return combine(join("Or", predicates), predicates, func(n *orgtree.Node) bool {
+		for _, p := range predicates {
+			if p.Match(n) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Not отрицает условие
+func Not(p Predicate) Predicate {
+	return combine("Not("+p.desc+")", []Predicate{p}, func(n *orgtree.Node) bool {
+		return !p.Match(n)
+	})
+}
+
+// IsOrgNode истинно для узлов оргструктуры
+func IsOrgNode() Predicate {
+	return New("IsOrgNode", func(n *orgtree.Node) bool {
+		v, ok := n.Value.(*orgtree.OrgNode)
+		return ok && v != nil
+	})
+}
+
+// IsEmployee истинно для сотрудников
+func IsEmployee() Predicate {
+	return New("IsEmployee", func(n *orgtree.Node) bool {
+		v, ok := n.Value.(*orgtree.EmployeeNode)
+		return ok && v != nil
+	})
+}
+
+// NameContains истинно, если имя OrgNode или EmployeeNode содержит подстроку
+func NameContains(substr string) Predicate {
+	return New(fmt.Sprintf("NameContains(%q)", substr), func(n *orgtree.Node) bool {
+		name, ok := nameOf(n)
+		return ok && strings.Contains(name, substr)
+	})
+}
+
+// NameMatches истинно, если имя OrgNode или EmployeeNode соответствует регулярному выражению
+func NameMatches(re *regexp.Regexp) Predicate {
+	return New(fmt.Sprintf("NameMatches(%q)", re.String()), func(n *orgtree.Node) bool {
+		name, ok := nameOf(n)
+		return ok && re.MatchString(name)
+	})
+}
+
+// SysNameIs истинно для OrgNode с данным системным именем
+func SysNameIs(sysName string) Predicate {
+	return New(fmt.Sprintf("SysNameIs(%q)", sysName), func(n *orgtree.Node) bool {
+		v, ok := n.Value.(*orgtree.OrgNode)
+		return ok && v != nil && v.SysName == sysName
+	})
+}
+
+// TypeIs истинно, если SysName типа OrgNode или EmployeeNode совпадает с данным
+func TypeIs(sysName string) Predicate {
+	return New(fmt.Sprintf("TypeIs(%q)", sysName), func(n *orgtree.Node) bool {
+		var t *orgtree.NodeType
+		switch v := n.Value.(type) {
+		case *orgtree.OrgNode:
+			if v != nil {
+				t = v.Type
+			}
+		case *orgtree.EmployeeNode:
+			if v != nil {
+				t = v.Type
+			}
+		}
+		return t != nil && t.SysName == sysName
+	})
+}
+
+// HasPosition истинно для OrgNode, у которого есть должность с данным системным именем
+func HasPosition(sysName string) Predicate {
+	return New(fmt.Sprintf("HasPosition(%q)", sysName), func(n *orgtree.Node) bool {
+		v, ok := n.Value.(*orgtree.OrgNode)
+		if !ok || v == nil {
+			return false
+		}
+		for _, p := range v.Positions {
+			if p != nil && p.SysName == sysName {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// HasChildren истинно для узлов, у которых есть дети
+func HasChildren() Predicate {
+	return NewStructural("HasChildren", func(n *orgtree.Node) bool {
+		return len(n.Children) > 0
+	})
+}
+
+// DepthBetween истинно для узлов, глубина которых от корня дерева лежит в [min, max].
+// Глубина считается как в orgtree.Stats: корень с nil-значением (заглушка TreeBuilder)
+// не учитывается, его дети имеют глубину 0, а сама заглушка не подходит ни под какой диапазон.
+func DepthBetween(min, max int) Predicate {
+	return NewStructural(fmt.Sprintf("DepthBetween(%d, %d)", min, max), func(n *orgtree.Node) bool {
+		depth := n.Depth()
+		if root := n.Root(); root.Value == nil {
+			if root == n {
+				return false
+			}
+			depth--
+		}
+		return depth >= min && depth <= max
+	})
+}
+
+// nameOf возвращает имя OrgNode или EmployeeNode
+func nameOf(n *orgtree.Node) (string, bool) {
+	switch v := n.Value.(type) {
+	case *orgtree.OrgNode:
+		if v != nil {
+			return v.Name, true
+		}
+	case *orgtree.EmployeeNode:
+		if v != nil {
+			return v.Name, true
+		}
+	}
+	return "", false
+}
+
+// combine создает составное условие, структурное, если структурна хотя бы одна из частей
+func combine(desc string, predicates []Predicate, match func(*orgtree.Node) bool) Predicate {
+	p := New(desc, match)
+	for _, part := range predicates {
+		p.structural = p.structural || part.structural
+	}
+	return p
+}
+
+func join(name string, predicates []Predicate) string {
+	parts := make([]string, len(predicates))
+	for i, p := range predicates {
+		parts[i] = p.desc
+	}
+	return name + "(" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,147 @@
+package predicates
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/arsants/orgtree"
+	"github.com/google/uuid"
+)
+
+func createTree() *orgtree.Node {
+	teamType := &orgtree.NodeType{ID: uuid.New(), Name: "Команда", SysName: "team"}
+	qaLead := &orgtree.Position{ID: uuid.New(), Name: "QA Lead", SysName: "qa_lead"}
+
+	root := orgtree.NewNode(&orgtree.OrgNode{ID: uuid.New(), Name: "IT отдел", SysName: "it_department"})
+	dev := orgtree.NewNode(&orgtree.OrgNode{ID: uuid.New(), Name: "Команда разработки", SysName: "dev_team", Type: teamType})
+	qa := orgtree.NewNode(&orgtree.OrgNode{ID: uuid.New(), Name: "Команда тестирования", SysName: "qa_team", Type: teamType, Positions: []*orgtree.Position{qaLead}})
+	ivan := orgtree.NewNode(&orgtree.EmployeeNode{ID: uuid.New(), Name: "Иван Петров"})
+
+	root.AddChild(dev)
+	root.AddChild(qa)
+	dev.AddChild(ivan)
+	return root
+}
+
+func names(root *orgtree.Node, p Predicate) []string {
+	var result []string
+	root.WalkTree(func(n *orgtree.Node, _ int) {
+		if p.Match(n) {
+			result = append(result, nameOfValue(n))
+		}
+	})
+	return result
+}
+
+func nameOfValue(n *orgtree.Node) string {
+	name, _ := nameOf(n)
+	return name
+}
+
+func assertNames(t *testing.T, p Predicate, got []string, expected ...string) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("%s: expected %v, got %v", p, expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("%s: expected %v, got %v", p, expected, got)
+		}
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	root := createTree()
+
+	tests := []struct {
+		predicate Predicate
+		expected  []string
+	}{
+		{IsOrgNode(), []string{"IT отдел", "Команда разработки", "Команда тестирования"}},
+		{IsEmployee(), []string{"Иван Петров"}},
+		{NameContains("Команда"), []string{"Команда разработки", "Команда тестирования"}},
+		{NameMatches(regexp.MustCompile(`^Иван`)), []string{"Иван Петров"}},
+		{SysNameIs("qa_team"), []string{"Команда тестирования"}},
+		{TypeIs("team"), []string{"Команда разработки", "Команда тестирования"}},
+		{HasPosition("qa_lead"), []string{"Команда тестирования"}},
+		{HasChildren(), []string{"IT отдел", "Команда разработки"}},
+		{DepthBetween(1, 1), []string{"Команда разработки", "Команда тестирования"}},
+		{And(TypeIs("team"), Not(HasPosition("qa_lead"))), []string{"Команда разработки"}},
+		{Or(IsEmployee(), SysNameIs("it_department")), []string{"IT отдел", "Иван Петров"}},
+		{And(), []string{"IT отдел", "Команда разработки", "Иван Петров", "Команда тестирования"}},
+		{Or(), nil},
+	}
+
+	for _, tt := range tests {
+		assertNames(t, tt.predicate, names(root, tt.predicate), tt.expected...)
+	}
+}
+
+func TestPredicateString(t *testing.T) {
+	p := And(IsOrgNode(), Or(TypeIs("team"), Not(NameContains("HR"))), DepthBetween(1, 2))
+	expected := `And(IsOrgNode, Or(TypeIs("team"), Not(NameContains("HR"))), DepthBetween(1, 2))`
+	if p.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, p)
+	}
+}
+
+func TestPredicatesWithFilters(t *testing.T) {
+	root := createTree()
+
+	// Структурные условия работают с FilterSubtreeBy
+	filtered := root.FilterSubtreeBy(And(HasChildren(), DepthBetween(1, 5)))
+	if filtered == nil || len(filtered.Children) != 1 || nameOfValue(filtered.Children[0]) != "Команда разработки" {
+		t.Fatalf("Unexpected filtered tree: %v", filtered)
+	}
+	if node := root.FilterBy(IsEmployee()); node == nil || nameOfValue(node) != "Иван Петров" {
+		t.Errorf("FilterBy returned %v", node)
+	}
+
+	// Условия по значению подключаются к FilterSubtree и Filter через Func
+	byPosition, err := HasPosition("qa_lead").Func()
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered = root.FilterSubtree(byPosition)
+	if filtered == nil || len(filtered.Children) != 1 || nameOfValue(filtered.Children[0]) != "Команда тестирования" {
+		t.Fatalf("Unexpected filtered tree: %v", filtered)
+	}
+	byType, err := TypeIs("team").Func()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node := root.Filter(byType); node == nil || nameOfValue(node) != "Команда разработки" {
+		t.Errorf("Filter returned %v", node)
+	}
+
+	// Структурные условия, в том числе внутри составных, по значению не проверяются
+	for _, p := range []Predicate{HasChildren(), Not(DepthBetween(0, 1)), And(IsOrgNode(), Or(HasChildren()))} {
+		if _, err := p.Func(); !errors.Is(err, ErrStructural) {
+			t.Errorf("%s: expected ErrStructural, got %v", p, err)
+		}
+	}
+}
+
+func TestDepthBetweenSkipsNilRoot(t *testing.T) {
+	// Заглушка TreeBuilder не учитывается, как и в orgtree.Stats
+	wrapper := orgtree.NewNode(nil)
+	root := createTree()
+	wrapper.AddChild(root)
+
+	p := DepthBetween(0, 0)
+	assertNames(t, p, names(wrapper, p), "IT отдел")
+	if p.Match(wrapper) || DepthBetween(-1, 5).Match(wrapper) {
+		t.Error("The nil wrapper root must not match")
+	}
+	if stats := orgtree.Stats(wrapper); stats.DepthHistogram[0] != 1 {
+		t.Errorf("Expected one node at depth 0 in Stats, got %v", stats.DepthHistogram)
+	}
+}
+
+func TestZeroPredicate(t *testing.T) {
+	var p Predicate
+	if p.Match(orgtree.NewNode("x")) || And(p).Match(orgtree.NewNode("x")) {
+		t.Error("Zero predicate must not match")
+	}
+}
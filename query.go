@@ -0,0 +1,380 @@
+package orgtree
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Axis задает, каких узлов касается шаг запроса
+type Axis int
+
+const (
+	// AxisChild — дети узлов предыдущего шага («/»)
+	AxisChild Axis = iota
+	// AxisDescendant — все потомки узлов предыдущего шага («//»)
+	AxisDescendant
+)
+
+// Операторы сравнения в условиях запроса
+const (
+	OpEq    = "="
+	OpNotEq = "!="
+	OpMatch = "~="
+)
+
+// QueryFields — поля, доступные в условиях запроса.
+// type — синоним type.sysname; positions — синоним positions.sysname;
+// kind принимает значения org и employee.
+var QueryFields = []string{
+	"name", "sysname", "id", "kind",
+	"type", "type.name", "type.sysname", "type.id",
+	"positions", "positions.name", "positions.sysname", "positions.id",
+}
+
+func isQueryField(field string) bool {
+	for _, f := range QueryFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Query — разобранный запрос к дереву
+type Query struct {
+	Steps  []QueryStep
+	source string
+}
+
+// QueryStep — шаг пути: ось, SysName узла (или «*») и условия
+type QueryStep struct {
+	Axis       Axis
+	Name       string
+	Predicates []QueryExpr
+}
+
+// QueryExpr — узел AST условия
+type QueryExpr interface {
+	// Match проверяет условие для узла дерева
+	Match(*Node) bool
+	// String возвращает условие в синтаксисе запроса
+	String() string
+}
+
+// AndExpr истинно, если истинны оба условия
+type AndExpr struct {
+	Left, Right QueryExpr
+}
+
+func (e *AndExpr) Match(n *Node) bool { return e.Left.Match(n) && e.Right.Match(n) }
+func (e *AndExpr) String() string {
+	return groupOr(e.Left) + " and " + groupOr(e.Right)
+}
+
+// OrExpr истинно, если истинно хотя бы одно из условий
+type OrExpr struct {
+	Left, Right QueryExpr
+}
+
+func (e *OrExpr) Match(n *Node) bool { return e.Left.Match(n) || e.Right.Match(n) }
+func (e *OrExpr) String() string     { return e.Left.String() + " or " + e.Right.String() }
+
+// NotExpr отрицает условие
+type NotExpr struct {
+	Expr QueryExpr
+}
+
+func (e *NotExpr) Match(n *Node) bool { return !e.Expr.Match(n) }
+func (e *NotExpr) String() string {
+	switch e.Expr.(type) {
+	case *AndExpr, *OrExpr:
+		return "not (" + e.Expr.String() + ")"
+	}
+	return "not " + e.Expr.String()
+}
+
+// groupOr заключает or в скобки, так как and связывает сильнее
+func groupOr(e QueryExpr) string {
+	if _, ok := e.(*OrExpr); ok {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+// ExistsExpr истинно, если у узла есть непустое значение поля
+type ExistsExpr struct {
+	Field string
+}
+
+func (e *ExistsExpr) Match(n *Node) bool { return len(queryFieldValues(n, e.Field)) > 0 }
+func (e *ExistsExpr) String() string     { return e.Field }
+
+// CompareExpr сравнивает поле узла со значением. Для многозначных полей (positions.*)
+// «=» и «~=» истинны, если подходит хотя бы одно значение, а «!=» — отрицание «=».
+type CompareExpr struct {
+	Field string
+	Op    string
+	Value string
+	re    *regexp.Regexp
+}
+
+func (e *CompareExpr) Match(n *Node) bool {
+	values := queryFieldValues(n, e.Field)
+	switch e.Op {
+	case OpNotEq:
+		return !containsString(values, e.Value)
+	case OpMatch:
+		for _, v := range values {
+			if e.re.MatchString(v) {
+				return true
+			}
+		}
+		return false
+	default:
+		return containsString(values, e.Value)
+	}
+}
+
+func (e *CompareExpr) String() string {
+	return e.Field + e.Op + quoteQueryValue(e.Value)
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteQueryValue заключает значение в кавычки, если оно не является идентификатором
+func quoteQueryValue(s string) string {
+	if s != "" && s != "and" && s != "or" && s != "not" && strings.IndexFunc(s, func(r rune) bool { return !isQueryIdentRune(r) }) < 0 {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// queryFieldValues возвращает непустые значения поля узла
+func queryFieldValues(n *Node, field string) []string {
+	var values []string
+	add := func(s string) {
+		if s != "" {
+			values = append(values, s)
+		}
+	}
+	addID := func(id uuid.UUID) {
+		if id != uuid.Nil {
+			values = append(values, id.String())
+		}
+	}
+
+	switch field {
+	case "name":
+		add(valueName(n.Value))
+	case "sysname":
+		add(valueSysName(n.Value))
+	case "id":
+		if id, ok := valueID(n.Value); ok {
+			addID(id)
+		}
+	case "kind":
+		switch n.Value.(type) {
+		case *OrgNode:
+			add("org")
+		case *EmployeeNode:
+			add("employee")
+		}
+	case "type", "type.sysname", "type.name", "type.id":
+		if t := valueType(n.Value); t != nil {
+			switch field {
+			case "type.name":
+				add(t.Name)
+			case "type.id":
+				addID(t.ID)
+			default:
+				add(t.SysName)
+			}
+		}
+	case "positions", "positions.sysname", "positions.name", "positions.id":
+		for _, p := range valuePositions(n.Value) {
+			if p == nil {
+				continue
+			}
+			switch field {
+			case "positions.name":
+				add(p.Name)
+			case "positions.id":
+				addID(p.ID)
+			default:
+				add(p.SysName)
+			}
+		}
+	}
+	return values
+}
+
+// matches проверяет имя и условия шага для узла
+func (s QueryStep) matches(n *Node) bool {
+	if s.Name != "*" && valueSysName(n.Value) != s.Name {
+		return false
+	}
+	for _, p := range s.Predicates {
+		if !p.Match(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// String возвращает шаг в синтаксисе запроса
+func (s QueryStep) String() string {
+	var b strings.Builder
+	if s.Axis == AxisDescendant {
+		b.WriteString("//")
+	} else {
+		b.WriteString("/")
+	}
+	if s.Name == "*" {
+		b.WriteString("*")
+	} else {
+		b.WriteString(quoteQueryValue(s.Name))
+	}
+	for _, p := range s.Predicates {
+		b.WriteString("[" + p.String() + "]")
+	}
+	return b.String()
+}
+
+// String возвращает запрос в каноническом виде, восстановленном по AST
+func (q *Query) String() string {
+	var b strings.Builder
+	for _, step := range q.Steps {
+		b.WriteString(step.String())
+	}
+	return b.String()
+}
+
+// Source возвращает исходный текст запроса
+func (q *Query) Source() string {
+	return q.source
+}
+
+// Select возвращает узлы, соответствующие запросу, в порядке прямого обхода, без повторов.
+// Корень с nil-значением (общий корень из BuildTree) считается узлом-документом:
+// первый шаг «/» выбирает его детей. Иначе первый шаг «/» выбирает сам root.
+func (q *Query) Select(root *Node) []*Node {
+	if root == nil {
+		return nil
+	}
+	doc := root
+	if root.Value != nil {
+		// Временный документ не меняет родителя root
+		doc = &Node{Children: []*Node{root}}
+	}
+
+	contexts := []*Node{doc}
+	for _, step := range q.Steps {
+		contexts = step.apply(contexts)
+		if len(contexts) == 0 {
+			return nil
+		}
+	}
+
+	if len(contexts) < 2 {
+		return contexts
+	}
+	// Шаги по потомкам могут нарушить порядок, восстанавливаем порядок документа
+	selected := make(map[*Node]bool, len(contexts))
+	for _, node := range contexts {
+		selected[node] = true
+	}
+	ordered := make([]*Node, 0, len(contexts))
+	for node := range doc.PreOrder() {
+		if selected[node] {
+			ordered = append(ordered, node)
+		}
+	}
+	return ordered
+}
+
+// apply применяет шаг к узлам предыдущего шага
+func (s QueryStep) apply(contexts []*Node) []*Node {
+	var result []*Node
+	seen := map[*Node]bool{}
+	add := func(n *Node) {
+		if !seen[n] && s.matches(n) {
+			seen[n] = true
+			result = append(result, n)
+		}
+	}
+
+	if s.Axis == AxisChild {
+		for _, c := range contexts {
+			for _, child := range c.Children {
+				add(child)
+			}
+		}
+		return result
+	}
+
+	// Потомки вложенных контекстов обходятся один раз
+	expanded := map[*Node]bool{}
+	for _, c := range contexts {
+		if expanded[c] {
+			continue
+		}
+		stack := append([]*Node{}, c.Children...)
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if expanded[n] {
+				continue
+			}
+			expanded[n] = true
+			add(n)
+			for i := len(n.Children) - 1; i >= 0; i-- {
+				stack = append(stack, n.Children[i])
+			}
+		}
+	}
+	return result
+}
+
+// Filter возвращает дерево, обрезанное как в FilterSubtree: найденные узлы и их предки
+func (q *Query) Filter(root *Node) *Node {
+	if root == nil {
+		return nil
+	}
+	selected := map[*Node]bool{}
+	for _, node := range q.Select(root) {
+		selected[node] = true
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+	filtered, _ := root.filterNodes(context.Background(), func(n *Node) bool { return selected[n] })
+	return filtered
+}
+
+// Select разбирает запрос и возвращает найденные узлы поддерева
+func (n *Node) Select(query string) ([]*Node, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return q.Select(n), nil
+}
+
+// SelectSubtree разбирает запрос и возвращает обрезанное дерево с найденными узлами
+func (n *Node) SelectSubtree(query string) (*Node, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return q.Filter(n), nil
+}
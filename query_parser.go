@@ -0,0 +1,304 @@
+package orgtree
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrQuerySyntax возвращается для запроса, который не удалось разобрать
+var ErrQuerySyntax = errors.New("orgtree: синтаксическая ошибка запроса")
+
+// queryTokenKind описывает вид лексемы запроса
+type queryTokenKind int
+
+const (
+	tokEOF queryTokenKind = iota
+	tokSlash
+	tokDoubleSlash
+	tokLBracket
+	tokRBracket
+	tokLParen
+	tokRParen
+	tokStar
+	tokOp
+	tokIdent
+	tokString
+)
+
+// queryToken — лексема запроса с позицией в исходной строке
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+// lexQuery разбивает запрос на лексемы
+func lexQuery(src string) ([]queryToken, error) {
+	var tokens []queryToken
+	for pos := 0; pos < len(src); {
+		r, size := utf8.DecodeRuneInString(src[pos:])
+		switch {
+		case unicode.IsSpace(r):
+			pos += size
+		case strings.HasPrefix(src[pos:], "//"):
+			tokens = append(tokens, queryToken{tokDoubleSlash, "//", pos})
+			pos += 2
+		case r == '/':
+			tokens = append(tokens, queryToken{tokSlash, "/", pos})
+			pos++
+		case r == '[':
+			tokens = append(tokens, queryToken{tokLBracket, "[", pos})
+			pos++
+		case r == ']':
+			tokens = append(tokens, queryToken{tokRBracket, "]", pos})
+			pos++
+		case r == '(':
+			tokens = append(tokens, queryToken{tokLParen, "(", pos})
+			pos++
+		case r == ')':
+			tokens = append(tokens, queryToken{tokRParen, ")", pos})
+			pos++
+		case r == '*':
+			tokens = append(tokens, queryToken{tokStar, "*", pos})
+			pos++
+		case r == '=':
+			tokens = append(tokens, queryToken{tokOp, OpEq, pos})
+			pos++
+		case strings.HasPrefix(src[pos:], OpNotEq), strings.HasPrefix(src[pos:], OpMatch):
+			tokens = append(tokens, queryToken{tokOp, src[pos : pos+2], pos})
+			pos += 2
+		case r == '"' || r == '\'':
+			text, end, err := lexQueryString(src, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{tokString, text, pos})
+			pos = end
+		case isQueryIdentRune(r):
+			start := pos
+			for pos < len(src) {
+				r, size := utf8.DecodeRuneInString(src[pos:])
+				if !isQueryIdentRune(r) {
+					break
+				}
+				pos += size
+			}
+			tokens = append(tokens, queryToken{tokIdent, src[start:pos], start})
+		default:
+			return nil, fmt.Errorf("%w: позиция %d: неожиданный символ %q", ErrQuerySyntax, pos, r)
+		}
+	}
+	return append(tokens, queryToken{tokEOF, "", len(src)}), nil
+}
+
+// lexQueryString читает строку в кавычках; обратная косая черта экранирует следующий символ
+func lexQueryString(src string, start int) (string, int, error) {
+	quote := src[start]
+	var b strings.Builder
+	for pos := start + 1; pos < len(src); pos++ {
+		switch c := src[pos]; {
+		case c == '\\' && pos+1 < len(src):
+			pos++
+			b.WriteByte(src[pos])
+		case c == quote:
+			return b.String(), pos + 1, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("%w: позиция %d: незакрытая строка", ErrQuerySyntax, start)
+}
+
+func isQueryIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+// queryParser разбирает лексемы в AST методом рекурсивного спуска
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+// ParseQuery разбирает запрос вида /main_office/it_department//*[type=team and positions.sysname=qa_lead].
+//
+// Запрос состоит из шагов: «/имя» выбирает детей, «//имя» — всех потомков с данным SysName,
+// «*» соответствует любому узлу. Каждый шаг может содержать условия в квадратных скобках
+// с операторами =, != и ~= (регулярное выражение), связками and, or, not и скобками.
+// Поле без оператора проверяет, что значение непустое. Поддерживаемые поля перечислены в QueryFields.
+func ParseQuery(src string) (*Query, error) {
+	tokens, err := lexQuery(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+
+	q := &Query{source: src}
+	for p.peek().kind != tokEOF {
+		step, err := p.parseStep()
+		if err != nil {
+			return nil, err
+		}
+		q.Steps = append(q.Steps, step)
+	}
+	if len(q.Steps) == 0 {
+		return nil, fmt.Errorf("%w: пустой запрос", ErrQuerySyntax)
+	}
+	return q, nil
+}
+
+// MustParseQuery разбирает запрос и паникует при ошибке; удобно для запросов-констант
+func MustParseQuery(src string) *Query {
+	q, err := ParseQuery(src)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) advance() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) errorf(tok queryToken, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: позиция %d: %s", ErrQuerySyntax, tok.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *queryParser) parseStep() (QueryStep, error) {
+	var step QueryStep
+	switch tok := p.advance(); tok.kind {
+	case tokSlash:
+		step.Axis = AxisChild
+	case tokDoubleSlash:
+		step.Axis = AxisDescendant
+	default:
+		return step, p.errorf(tok, "ожидался / или //, получено %q", tok.text)
+	}
+
+	switch tok := p.advance(); tok.kind {
+	case tokStar:
+		step.Name = "*"
+	case tokIdent, tokString:
+		step.Name = tok.text
+	default:
+		return step, p.errorf(tok, "ожидалось имя узла или *, получено %q", tok.text)
+	}
+
+	for p.peek().kind == tokLBracket {
+		p.advance()
+		expr, err := p.parseOr()
+		if err != nil {
+			return step, err
+		}
+		if tok := p.advance(); tok.kind != tokRBracket {
+			return step, p.errorf(tok, "ожидалась ], получено %q", tok.text)
+		}
+		step.Predicates = append(step.Predicates, expr)
+	}
+	return step, nil
+}
+
+// isKeyword проверяет, что следующая лексема — ключевое слово word
+func (p *queryParser) isKeyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == tokIdent && tok.text == word
+}
+
+func (p *queryParser) parseOr() (QueryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &OrExpr{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (QueryExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.advance()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &AndExpr{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (QueryExpr, error) {
+	if p.isKeyword("not") {
+		p.advance()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{Expr: expr}, nil
+	}
+
+	if p.peek().kind == tokLParen {
+		p.advance()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.advance(); tok.kind != tokRParen {
+			return nil, p.errorf(tok, "ожидалась ), получено %q", tok.text)
+		}
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *queryParser) parseComparison() (QueryExpr, error) {
+	fieldTok := p.advance()
+	if fieldTok.kind != tokIdent {
+		return nil, p.errorf(fieldTok, "ожидалось поле, получено %q", fieldTok.text)
+	}
+	field := fieldTok.text
+	if !isQueryField(field) {
+		return nil, p.errorf(fieldTok, "неизвестное поле %q", field)
+	}
+
+	if p.peek().kind != tokOp {
+		return &ExistsExpr{Field: field}, nil
+	}
+	op := p.advance().text
+
+	valueTok := p.advance()
+	if valueTok.kind != tokIdent && valueTok.kind != tokString {
+		return nil, p.errorf(valueTok, "ожидалось значение, получено %q", valueTok.text)
+	}
+
+	expr := &CompareExpr{Field: field, Op: op, Value: valueTok.text}
+	if op == OpMatch {
+		re, err := regexp.Compile(valueTok.text)
+		if err != nil {
+			return nil, p.errorf(valueTok, "некорректное регулярное выражение: %v", err)
+		}
+		expr.re = re
+	}
+	return expr, nil
+}
@@ -0,0 +1,161 @@
+package orgtree
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// createQueryTree строит дерево main_office → it_department/hr_department → команды → сотрудники
+// под общим корнем с nil-значением, как BuildTree
+func createQueryTree() *Node {
+	departmentType, teamType, employeeType := createTestNodeTypes()
+	qaLead := &Position{ID: uuid.New(), Name: "QA Lead", SysName: "qa_lead"}
+	devLead := &Position{ID: uuid.New(), Name: "Dev Lead", SysName: "dev_lead"}
+
+	org := func(name, sysName string, typ *NodeType, positions ...*Position) *Node {
+		return NewNode(&OrgNode{ID: uuid.New(), Name: name, SysName: sysName, Type: typ, Positions: positions})
+	}
+
+	root := NewNode(nil)
+	office := org("Главный офис", "main_office", departmentType)
+	it := org("IT отдел", "it_department", departmentType)
+	hr := org("HR отдел", "hr_department", departmentType)
+	dev := org("Команда разработки", "dev_team", teamType, devLead)
+	qa := org("Команда тестирования", "qa_team", teamType, qaLead)
+	mobile := org("Mobile команда", "mobile_team", teamType, devLead, qaLead)
+	recruiting := org("Рекрутинг", "recruiting_team", teamType)
+
+	root.AddChild(office)
+	office.AddChild(it)
+	office.AddChild(hr)
+	it.AddChild(dev)
+	it.AddChild(qa)
+	dev.AddChild(mobile)
+	hr.AddChild(recruiting)
+	dev.AddChild(NewNode(&EmployeeNode{ID: uuid.New(), Name: "Иван", Type: employeeType}))
+	return root
+}
+
+func TestQuerySelect(t *testing.T) {
+	root := createQueryTree()
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"/main_office", []string{"Главный офис"}},
+		{"/main_office/*", []string{"IT отдел", "HR отдел"}},
+		{"/main_office/it_department//*[type=team]", []string{"Команда разработки", "Mobile команда", "Команда тестирования"}},
+		{"/main_office/it_department//*[type=team][positions.sysname=qa_lead]", []string{"Mobile команда", "Команда тестирования"}},
+		{"//*[type=team and positions=dev_lead and not positions=qa_lead]", []string{"Команда разработки"}},
+		{"//*[sysname=qa_team or sysname=recruiting_team]", []string{"Команда тестирования", "Рекрутинг"}},
+		{"//*[kind=employee]", []string{"Иван"}},
+		{"//*[type=team][not positions]", []string{"Рекрутинг"}},
+		{`//*[name~="^Команда"]`, []string{"Команда разработки", "Команда тестирования"}},
+		{"//*[type.name=Team and (positions.name='QA Lead' or sysname=recruiting_team)]", []string{"Команда тестирования", "Mobile команда", "Рекрутинг"}},
+		{"//dev_team//*", []string{"Mobile команда", "Иван"}},
+		{"//it_department/qa_team", []string{"Команда тестирования"}},
+		{"/it_department", nil},
+		{"//*[positions!=dev_lead]/*", []string{"Команда разработки", "Команда тестирования", "Рекрутинг", "IT отдел", "HR отдел"}},
+	}
+
+	for _, tt := range tests {
+		nodes, err := root.Select(tt.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		// Результат всегда в порядке прямого обхода
+		expected := map[string]bool{}
+		for _, name := range tt.expected {
+			expected[name] = true
+		}
+		got := orgNames(nodes)
+		if len(got) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.query, tt.expected, got)
+			continue
+		}
+		for _, name := range got {
+			if !expected[name] {
+				t.Errorf("%s: expected %v, got %v", tt.query, tt.expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestQueryDocumentOrderAndRoot(t *testing.T) {
+	root := createQueryTree()
+
+	nodes, _ := root.Select("//*[type=team]")
+	assertNames(t, orgNames(nodes), "Команда разработки", "Mobile команда", "Команда тестирования", "Рекрутинг")
+
+	// Для корня со значением первый шаг выбирает сам корень
+	office := root.Children[0]
+	nodes, _ = office.Select("/main_office/hr_department")
+	assertNames(t, orgNames(nodes), "HR отдел")
+	if office.Parent() != root {
+		t.Error("Select must not change parent pointers")
+	}
+}
+
+func TestQueryFilter(t *testing.T) {
+	root := createQueryTree()
+
+	filtered, err := root.SelectSubtree("//*[positions=qa_lead]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	office := filtered.Children[0]
+	assertNames(t, orgNames(office.Children), "IT отдел")
+	it := office.Children[0]
+	assertNames(t, orgNames(it.Children), "Команда разработки", "Команда тестирования")
+	assertNames(t, orgNames(it.Children[0].Children), "Mobile команда")
+
+	if filtered, _ := root.SelectSubtree("//nothing"); filtered != nil {
+		t.Error("Expected nil for empty selection")
+	}
+}
+
+func TestQueryParseAndString(t *testing.T) {
+	q, err := ParseQuery(`/main_office//*[type=team and (positions=qa_lead or not name~="^Mo")][name!="a b"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Steps) != 2 || q.Steps[1].Axis != AxisDescendant || len(q.Steps[1].Predicates) != 2 {
+		t.Fatalf("Unexpected AST: %+v", q.Steps)
+	}
+	if _, ok := q.Steps[1].Predicates[0].(*AndExpr); !ok {
+		t.Errorf("Expected and at the top of the first predicate, got %T", q.Steps[1].Predicates[0])
+	}
+
+	expected := `/main_office//*[type=team and (positions=qa_lead or not name~="^Mo")][name!="a b"]`
+	if q.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, q.String())
+	}
+	// Каноническая форма разбирается в тот же запрос
+	if again := MustParseQuery(q.String()); again.String() != expected {
+		t.Errorf("Round trip changed the query: %s", again.String())
+	}
+}
+
+func TestQuerySyntaxErrors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"main_office",
+		"/",
+		"/a[",
+		"/a[type=]",
+		"/a[unknown=1]",
+		"/a[name~='(']",
+		"/a[type=team and]",
+		"/a[(type=team]",
+		"/a['unterminated]",
+		"/a#",
+	} {
+		if _, err := ParseQuery(query); !errors.Is(err, ErrQuerySyntax) {
+			t.Errorf("%q: expected ErrQuerySyntax, got %v", query, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package orgtree
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Rollup содержит агрегаты поддерева узла, включая сам узел
+type Rollup struct {
+	// Employees — число сотрудников (EmployeeNode)
+	Employees int `json:"employees"`
+	// Positions — число должностей всех OrgNode
+	Positions int `json:"positions"`
+	// Units — число подразделений (непустых OrgNode) ниже узла, без него самого
+	Units int `json:"units"`
+	// Metrics — суммы пользовательских числовых атрибутов по имени
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+}
+
+// RollupMetric возвращает собственное значение числового атрибута узла (бюджет, ставки).
+// Для поддерева значения суммируются.
+type RollupMetric func(*Node) float64
+
+// Rollups вычисляет агрегаты для каждого узла с непустым ID (OrgNode или EmployeeNode) за один
+// обход снизу вверх. Узлы без ID, например общий корень из BuildTree, учитываются
+// в агрегатах предков, но в результат не попадают.
+func Rollups(root *Node, metrics map[string]RollupMetric) map[uuid.UUID]Rollup {
+	result := map[uuid.UUID]Rollup{}
+	if root == nil {
+		return result
+	}
+
+	FoldUp(root, func(n *Node, children []Rollup) Rollup {
+		var r Rollup
+		if len(metrics) > 0 {
+			r.Metrics = make(map[string]float64, len(metrics))
+			for name, metric := range metrics {
+				r.Metrics[name] = metric(n)
+			}
+		}
+
+		for i, child := range children {
+			r.Employees += child.Employees
+			r.Positions += child.Positions
+			r.Units += child.Units
+			if org, ok := n.Children[i].Value.(*OrgNode); ok && org != nil {
+				r.Units++
+			}
+			for name, value := range child.Metrics {
+				r.Metrics[name] += value
+			}
+		}
+
+		switch v := n.Value.(type) {
+		case *OrgNode:
+			if v != nil {
+				r.Positions += len(v.Positions)
+			}
+		case *EmployeeNode:
+			if v != nil {
+				r.Employees++
+			}
+		}
+
+		if id, ok := valueID(n.Value); ok && id != uuid.Nil {
+			result[id] = r
+		}
+		return r
+	})
+	return result
+}
+
+// String возвращает агрегаты в виде «сотрудников: 3, должностей: 2, подразделений: 1»;
+// пользовательские атрибуты следуют по алфавиту
+func (r Rollup) String() string {
+	parts := []string{
+		fmt.Sprintf("сотрудников: %d", r.Employees),
+		fmt.Sprintf("должностей: %d", r.Positions),
+		fmt.Sprintf("подразделений: %d", r.Units),
+	}
+	names := make([]string, 0, len(r.Metrics))
+	for name := range r.Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %g", name, r.Metrics[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PrintTreeWithRollups выводит дерево в консоль, добавляя агрегаты к названиям подразделений
+func (n *Node) PrintTreeWithRollups(rollups map[uuid.UUID]Rollup) error {
+	return n.WriteTreeWithRollups(os.Stdout, rollups)
+}
+
+// WriteTreeWithRollups выводит дерево в w как PrintTreeWithRollups.
+// Узлы с ID подписываются именем, у OrgNode из rollups агрегаты указываются в скобках.
+func (n *Node) WriteTreeWithRollups(w io.Writer, rollups map[uuid.UUID]Rollup) error {
+	return n.writeTree(w, func(node *Node) string {
+		id, ok := valueID(node.Value)
+		if !ok {
+			return fmt.Sprint(node.Value)
+		}
+		if _, isOrg := node.Value.(*OrgNode); isOrg {
+			if r, ok := rollups[id]; ok {
+				return fmt.Sprintf("%s [%s]", valueName(node.Value), r)
+			}
+		}
+		return valueName(node.Value)
+	})
+}
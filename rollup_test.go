@@ -0,0 +1,71 @@
+package orgtree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRollups(t *testing.T) {
+	root, ids := createIndexedTree()
+	_, devPos, qaPos := createTestPositions()
+	it := root.Children[0]
+	dev, qa := it.Children[0], it.Children[1]
+	dev.Value.(*OrgNode).Positions = []*Position{devPos}
+	qa.Value.(*OrgNode).Positions = []*Position{qaPos, devPos}
+	qa.AddChild(NewNode(&EmployeeNode{Name: "Петр"}))
+
+	budgets := map[string]float64{"dev_team": 100, "qa_team": 50, "it_department": 10}
+	rollups := Rollups(root, map[string]RollupMetric{
+		"budget": func(n *Node) float64 { return budgets[valueSysName(n.Value)] },
+	})
+
+	itRollup := rollups[ids["it_department"]]
+	if itRollup.Employees != 2 || itRollup.Positions != 3 || itRollup.Units != 2 {
+		t.Errorf("Unexpected IT rollup: %+v", itRollup)
+	}
+	if itRollup.Metrics["budget"] != 160 {
+		t.Errorf("Expected IT budget 160, got %v", itRollup.Metrics["budget"])
+	}
+
+	devRollup := rollups[ids["dev_team"]]
+	if devRollup.Employees != 1 || devRollup.Positions != 1 || devRollup.Units != 0 || devRollup.Metrics["budget"] != 100 {
+		t.Errorf("Unexpected dev rollup: %+v", devRollup)
+	}
+	if hr := rollups[ids["hr_department"]]; hr.Employees != 0 || hr.Metrics["budget"] != 0 {
+		t.Errorf("Unexpected HR rollup: %+v", hr)
+	}
+	// Пустой *OrgNode не считается подразделением
+	it.AddChild(NewNode((*OrgNode)(nil)))
+	if r := Rollups(root, nil)[ids["it_department"]]; r.Units != 2 {
+		t.Errorf("Expected nil OrgNode to be skipped, got %d units", r.Units)
+	}
+
+	if len(rollups) != 5 {
+		t.Errorf("Expected rollups only for nodes with ID, got %d", len(rollups))
+	}
+
+	if s := devRollup.String(); s != "сотрудников: 1, должностей: 1, подразделений: 0, budget: 100" {
+		t.Errorf("Unexpected rollup string: %s", s)
+	}
+}
+
+func TestWriteTreeWithRollups(t *testing.T) {
+	root, _ := createIndexedTree()
+	rollups := Rollups(root, nil)
+
+	var buf bytes.Buffer
+	if err := root.WriteTreeWithRollups(&buf, rollups); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `└── <nil>
+    ├── IT отдел [сотрудников: 1, должностей: 0, подразделений: 2]
+    │   ├── Команда разработки [сотрудников: 1, должностей: 0, подразделений: 0]
+    │   │   └── Иван
+    │   └── Команда тестирования [сотрудников: 0, должностей: 0, подразделений: 0]
+    └── HR отдел [сотрудников: 0, должностей: 0, подразделений: 0]
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s", buf.String())
+	}
+}
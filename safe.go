@@ -0,0 +1,266 @@
+package orgtree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrSharedNode возвращается, если узел достижим из нескольких родителей
+var ErrSharedNode = errors.New("orgtree: узел имеет несколько родителей")
+
+// ErrCycleDetected возвращается проверками, нашедшими уже существующий цикл,
+// в отличие от ErrCycle для операций, которые создали бы его.
+// Для совместимости errors.Is(ErrCycleDetected, ErrCycle) возвращает true.
+var ErrCycleDetected error = cycleDetectedError{}
+
+type cycleDetectedError struct{}
+
+func (cycleDetectedError) Error() string        { return "orgtree: обнаружен цикл" }
+func (cycleDetectedError) Is(target error) bool { return target == ErrCycle }
+
+// CycleError описывает узел, который встречается среди собственных потомков.
+// errors.Is(err, ErrCycleDetected) и errors.Is(err, ErrCycle) возвращают true.
+type CycleError struct {
+	// Node — узел, на который ведет обратная связь
+	Node *Node
+	// Path — путь от корня обхода до повторного вхождения Node включительно
+	Path []*Node
+}
+
+func (e *CycleError) Error() string {
+	parent := e.Path[len(e.Path)-2]
+	return fmt.Sprintf("orgtree: цикл: %s является потомком %s", describeNode(parent), describeNode(e.Node))
+}
+
+func (e *CycleError) Is(target error) bool {
+	return target == ErrCycleDetected || target == ErrCycle
+}
+
+// SharedNodeError описывает узел, у которого больше одного родителя.
+// errors.Is(err, ErrSharedNode) возвращает true.
+type SharedNodeError struct {
+	// Node — узел, встреченный повторно
+	Node *Node
+	// Parents — родитель, через которого узел был посещен первым, и повторный родитель
+	Parents []*Node
+}
+
+func (e *SharedNodeError) Error() string {
+	return fmt.Sprintf("orgtree: узел %s имеет несколько родителей: %s и %s",
+		describeNode(e.Node), describeNode(e.Parents[0]), describeNode(e.Parents[1]))
+}
+
+func (e *SharedNodeError) Is(target error) bool {
+	return target == ErrSharedNode
+}
+
+// describeNode возвращает читаемое описание узла для сообщений об ошибках
+func describeNode(n *Node) string {
+	if n == nil {
+		return "<nil>"
+	}
+	if id, ok := valueID(n.Value); ok {
+		return fmt.Sprintf("%q (%s)", valueName(n.Value), id)
+	}
+	return fmt.Sprintf("%v", n.Value)
+}
+
+// ValidateTree проверяет, что поддерево root является деревом, и возвращает все проблемы:
+// *CycleError для каждой обратной связи и *SharedNodeError для каждого повторного родителя.
+// Проверка не зацикливается и не заходит в уже посещенные узлы.
+func ValidateTree(root *Node) []error {
+	return inspectTree(root, false)
+}
+
+// CheckTree возвращает первую проблему, которую нашел бы ValidateTree, или nil
+func CheckTree(root *Node) error {
+	if errs := inspectTree(root, true); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// inspectTree обходит поддерево в глубину, отмечая узлы на текущем пути и уже посещенные
+func inspectTree(root *Node, firstOnly bool) []error {
+	if root == nil {
+		return nil
+	}
+
+	var errs []error
+	visited := map[*Node]*Node{root: nil}
+	onPath := map[*Node]bool{root: true}
+	stack := []postOrderFrame{{node: root}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.next == len(top.node.Children) {
+			delete(onPath, top.node)
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		parent := top.node
+		child := parent.Children[top.next]
+		top.next++
+		if child == nil {
+			continue
+		}
+
+		if onPath[child] {
+			path := make([]*Node, 0, len(stack)+1)
+			for _, frame := range stack {
+				path = append(path, frame.node)
+			}
+			errs = append(errs, &CycleError{Node: child, Path: append(path, child)})
+		} else if first, ok := visited[child]; ok {
+			errs = append(errs, &SharedNodeError{Node: child, Parents: []*Node{first, parent}})
+		} else {
+			visited[child] = parent
+			onPath[child] = true
+			stack = append(stack, postOrderFrame{node: child})
+			continue
+		}
+		if firstOnly {
+			return errs
+		}
+	}
+	return errs
+}
+
+// SafeIterator перед первым вызовом Next проверяет поддерево через CheckTree
+// и при обнаружении цикла или общего узла не выдает ни одного узла.
+// Проверка выполняется один раз, поэтому дерево не должно меняться во время обхода.
+type SafeIterator struct {
+	root    *Node
+	it      Iterator
+	checked bool
+	err     error
+}
+
+// NewSafeIterator оборачивает итератор it по поддереву root безопасной проверкой
+func NewSafeIterator(root *Node, it Iterator) *SafeIterator {
+	return &SafeIterator{root: root, it: it}
+}
+
+// NewSafePreOrderIterator возвращает прямой итератор с проверкой структуры
+func NewSafePreOrderIterator(root *Node) *SafeIterator {
+	return NewSafeIterator(root, NewPreOrderIterator(root))
+}
+
+// NewSafePostOrderIterator возвращает обратный итератор с проверкой структуры
+func NewSafePostOrderIterator(root *Node) *SafeIterator {
+	return NewSafeIterator(root, NewPostOrderIterator(root))
+}
+
+// NewSafeBFSIterator возвращает итератор в ширину с проверкой структуры
+func NewSafeBFSIterator(root *Node) *SafeIterator {
+	return NewSafeIterator(root, NewBFSIterator(root))
+}
+
+// Next возвращает следующий узел или nil, если обход закончен или структура некорректна
+func (it *SafeIterator) Next() *Node {
+	if !it.checked {
+		it.checked = true
+		it.err = CheckTree(it.root)
+	}
+	if it.err != nil {
+		return nil
+	}
+	return it.it.Next()
+}
+
+// Err возвращает *CycleError или *SharedNodeError, если обход не был начат
+func (it *SafeIterator) Err() error {
+	return it.err
+}
+
+// WalkTreeSafe обходит дерево как WalkTree, если в нем нет циклов и общих узлов
+func (n *Node) WalkTreeSafe(callback func(*Node, int)) error {
+	if err := CheckTree(n); err != nil {
+		return err
+	}
+	return n.walkTree(context.Background(), callback)
+}
+
+// FilterSubtreeSafe фильтрует дерево как FilterSubtree, если в нем нет циклов и общих узлов
+func (n *Node) FilterSubtreeSafe(predicate func(interface{}) bool) (*Node, error) {
+	if err := CheckTree(n); err != nil {
+		return nil, err
+	}
+	return n.FilterSubtree(predicate), nil
+}
+
+// FindSafe ищет узел как Find, если в дереве нет циклов и общих узлов
+func (n *Node) FindSafe(value interface{}) (*Node, error) {
+	if err := CheckTree(n); err != nil {
+		return nil, err
+	}
+	return n.Find(value), nil
+}
+
+// HashSafe вычисляет хеш как Hash, если в дереве нет циклов и общих узлов
+func (n *Node) HashSafe() ([]byte, error) {
+	if err := CheckTree(n); err != nil {
+		return nil, err
+	}
+	return n.Hash(), nil
+}
+
+// Validate проверяет связи построителя до вызова BuildTree: узлы с несколькими
+// входящими связями (ErrSharedNode) и циклы (ErrCycleDetected), в том числе недостижимые
+// из корней. Связи с неизвестными узлами игнорируются, как и в BuildTree.
+func (tb *TreeBuilder) Validate() []error {
+	known := func(id uuid.UUID) bool {
+		_, org := tb.nodes[id]
+		_, employee := tb.employeeNodes[id]
+		return org || employee
+	}
+	label := func(id uuid.UUID) string {
+		if employee, ok := tb.employeeNodes[id]; ok {
+			return fmt.Sprintf("%q (%s)", employee.Name, id)
+		}
+		return fmt.Sprintf("%q (%s)", tb.nodes[id].Name, id)
+	}
+
+	var errs []error
+	parentOf := map[uuid.UUID]uuid.UUID{}
+	for _, edge := range tb.edges {
+		if !known(edge.FromNode) || !known(edge.ToNode) {
+			continue
+		}
+		if first, ok := parentOf[edge.ToNode]; ok {
+			errs = append(errs, fmt.Errorf("%w: %s: %s и %s", ErrSharedNode,
+				label(edge.ToNode), label(first), label(edge.FromNode)))
+			continue
+		}
+		parentOf[edge.ToNode] = edge.FromNode
+	}
+
+	// После отбрасывания повторных связей у каждого узла не больше одного родителя,
+	// поэтому цикл находится подъемом по родителям
+	state := map[uuid.UUID]int{} // 1 — на текущем подъеме, 2 — проверен
+	for _, id := range tb.orderedIDs() {
+		var chain []uuid.UUID
+		current := id
+		for state[current] == 0 {
+			state[current] = 1
+			chain = append(chain, current)
+			parent, ok := parentOf[current]
+			if !ok {
+				break
+			}
+			current = parent
+		}
+		if state[current] == 1 && len(chain) > 0 {
+			if _, ok := parentOf[chain[len(chain)-1]]; ok {
+				errs = append(errs, fmt.Errorf("%w: %s является собственным предком", ErrCycleDetected, label(current)))
+			}
+		}
+		for _, visited := range chain {
+			state[visited] = 2
+		}
+	}
+	return errs
+}
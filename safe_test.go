@@ -0,0 +1,116 @@
+package orgtree
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateTreeReportsProblems(t *testing.T) {
+	root, _ := createIndexedTree()
+	if errs := ValidateTree(root); len(errs) != 0 {
+		t.Fatalf("Expected valid tree, got %v", errs)
+	}
+
+	it, hr := root.Children[0], root.Children[1]
+	dev := it.Children[0]
+	ivan := dev.Children[0]
+
+	// Сотрудник оказывается сразу в двух командах, а IT отдел — потомком самого себя
+	hr.Children = append(hr.Children, ivan)
+	ivan.Children = append(ivan.Children, it)
+
+	errs := ValidateTree(root)
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 problems, got %v", errs)
+	}
+
+	var cycle *CycleError
+	if !errors.As(errs[0], &cycle) || !errors.Is(errs[0], ErrCycle) || !errors.Is(errs[0], ErrCycleDetected) {
+		t.Fatalf("Expected CycleError first, got %v", errs[0])
+	}
+	if cycle.Node != it || len(cycle.Path) != 5 || cycle.Path[3] != ivan {
+		t.Errorf("Unexpected cycle: %v", cycle)
+	}
+
+	var shared *SharedNodeError
+	if !errors.As(errs[1], &shared) || !errors.Is(errs[1], ErrSharedNode) {
+		t.Fatalf("Expected SharedNodeError second, got %v", errs[1])
+	}
+	if shared.Node != ivan || shared.Parents[0] != dev || shared.Parents[1] != hr {
+		t.Errorf("Unexpected shared node error: %v", shared)
+	}
+	if err := CheckTree(root); err == nil || err.Error() != errs[0].Error() {
+		t.Error("Expected CheckTree to return the first problem")
+	}
+}
+
+func TestSafeTraversals(t *testing.T) {
+	a := NewNode("a")
+	b := NewNode("b")
+	a.Children = []*Node{b}
+	b.Children = []*Node{a}
+
+	for name, it := range map[string]*SafeIterator{
+		"pre":  NewSafePreOrderIterator(a),
+		"post": NewSafePostOrderIterator(a),
+		"bfs":  NewSafeBFSIterator(a),
+	} {
+		if node := it.Next(); node != nil || !errors.Is(it.Err(), ErrCycle) {
+			t.Errorf("%s: expected cycle error, got %v and %v", name, node, it.Err())
+		}
+	}
+
+	if err := a.WalkTreeSafe(func(*Node, int) {}); !errors.Is(err, ErrCycle) {
+		t.Errorf("WalkTreeSafe: expected cycle error, got %v", err)
+	}
+	if _, err := a.HashSafe(); !errors.Is(err, ErrCycle) {
+		t.Errorf("HashSafe: expected cycle error, got %v", err)
+	}
+	if _, err := a.FilterSubtreeSafe(func(interface{}) bool { return true }); !errors.Is(err, ErrCycle) {
+		t.Errorf("FilterSubtreeSafe: expected cycle error, got %v", err)
+	}
+	if _, err := a.FindSafe("b"); !errors.Is(err, ErrCycle) {
+		t.Errorf("FindSafe: expected cycle error, got %v", err)
+	}
+
+	// На корректном дереве безопасные варианты совпадают с обычными
+	root := createStringTree()
+	assertSeqValues(t, collectValues(NewSafePreOrderIterator(root)),
+		"root", "child1", "grandchild1", "child2", "grandchild2")
+	if sum, err := root.HashSafe(); err != nil || string(sum) != string(root.Hash()) {
+		t.Errorf("HashSafe differs from Hash: %v", err)
+	}
+}
+
+func TestTreeBuilderValidate(t *testing.T) {
+	builder := NewTreeBuilder()
+	nodes := map[string]*OrgNode{}
+	for _, name := range []string{"root", "a", "b", "c"} {
+		nodes[name] = &OrgNode{ID: uuid.New(), Name: name}
+		builder.AddNode(nodes[name])
+	}
+	edge := func(from, to string) {
+		builder.AddEdge(&Edge{FromNode: nodes[from].ID, ToNode: nodes[to].ID})
+	}
+	edge("root", "c")
+	if errs := builder.Validate(); len(errs) != 0 {
+		t.Fatalf("Expected no problems, got %v", errs)
+	}
+
+	// Недостижимый из корней цикл a → b → a и второй родитель у c
+	edge("a", "b")
+	edge("b", "a")
+	edge("a", "c")
+
+	errs := builder.Validate()
+	if len(errs) != 2 || !errors.Is(errs[0], ErrSharedNode) || !errors.Is(errs[1], ErrCycle) {
+		t.Fatalf("Expected shared node and cycle errors, got %v", errs)
+	}
+	// Существующий цикл не описывается как результат операции
+	if !errors.Is(errs[1], ErrCycleDetected) || strings.Contains(errs[1].Error(), ErrCycle.Error()) {
+		t.Errorf("Expected a detected cycle message, got %q", errs[1])
+	}
+}
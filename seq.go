@@ -0,0 +1,146 @@
+package orgtree
+
+import "iter"
+
+// PreOrder возвращает последовательность узлов в прямом порядке.
+// Обход прекращается, как только цикл range завершается (break, return).
+func (n *Node) PreOrder() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		for _, node := range n.PreOrderWithDepth() {
+			if !yield(node) {
+				return
+			}
+		}
+	}
+}
+
+// PreOrderWithDepth возвращает пары (глубина, узел) в прямом порядке
+func (n *Node) PreOrderWithDepth() iter.Seq2[int, *Node] {
+	return func(yield func(int, *Node) bool) {
+		type entry struct {
+			node  *Node
+			depth int
+		}
+		stack := []entry{{n, 0}}
+		for len(stack) > 0 {
+			e := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !yield(e.depth, e.node) {
+				return
+			}
+			for i := len(e.node.Children) - 1; i >= 0; i-- {
+				stack = append(stack, entry{e.node.Children[i], e.depth + 1})
+			}
+		}
+	}
+}
+
+// PostOrder возвращает последовательность узлов в обратном порядке (дети раньше родителя)
+func (n *Node) PostOrder() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		for _, node := range n.PostOrderWithDepth() {
+			if !yield(node) {
+				return
+			}
+		}
+	}
+}
+
+// PostOrderWithDepth возвращает пары (глубина, узел) в обратном порядке
+func (n *Node) PostOrderWithDepth() iter.Seq2[int, *Node] {
+	return func(yield func(int, *Node) bool) {
+		type frame struct {
+			node *Node
+			next int
+		}
+		stack := []frame{{node: n}}
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.next < len(top.node.Children) {
+				child := top.node.Children[top.next]
+				top.next++
+				stack = append(stack, frame{node: child})
+				continue
+			}
+			node := top.node
+			stack = stack[:len(stack)-1]
+			if !yield(len(stack), node) {
+				return
+			}
+		}
+	}
+}
+
+// BFS возвращает последовательность узлов в порядке обхода в ширину
+func (n *Node) BFS() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		for _, node := range n.BFSWithDepth() {
+			if !yield(node) {
+				return
+			}
+		}
+	}
+}
+
+// BFSWithDepth возвращает пары (глубина, узел) в порядке обхода в ширину
+func (n *Node) BFSWithDepth() iter.Seq2[int, *Node] {
+	return func(yield func(int, *Node) bool) {
+		level := []*Node{n}
+		for depth := 0; len(level) > 0; depth++ {
+			next := []*Node{}
+			for _, node := range level {
+				if !yield(depth, node) {
+					return
+				}
+				next = append(next, node.Children...)
+			}
+			level = next
+		}
+	}
+}
+
+// Filter возвращает последовательность элементов seq, удовлетворяющих предикату
+func Filter[V any](seq iter.Seq[V], predicate func(V) bool) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for v := range seq {
+			if predicate(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Map возвращает последовательность результатов fn для элементов seq
+func Map[V, R any](seq iter.Seq[V], fn func(V) R) iter.Seq[R] {
+	return func(yield func(R) bool) {
+		for v := range seq {
+			if !yield(fn(v)) {
+				return
+			}
+		}
+	}
+}
+
+// Take возвращает не более n первых элементов seq
+func Take[V any](seq iter.Seq[V], n int) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		if n <= 0 {
+			return
+		}
+		taken := 0
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			taken++
+			if taken >= n {
+				return
+			}
+		}
+	}
+}
+
+// Values возвращает последовательность значений узлов
+func Values(seq iter.Seq[*Node]) iter.Seq[interface{}] {
+	return Map(seq, func(n *Node) interface{} { return n.Value })
+}
@@ -0,0 +1,113 @@
+package orgtree
+
+import (
+	"iter"
+	"slices"
+	"testing"
+)
+
+func createStringTree() *Node {
+	root := NewNode("root")
+	child1 := NewNode("child1")
+	child2 := NewNode("child2")
+	root.AddChild(child1)
+	root.AddChild(child2)
+	child1.AddChild(NewNode("grandchild1"))
+	child2.AddChild(NewNode("grandchild2"))
+	return root
+}
+
+func assertSeqValues(t *testing.T, got []interface{}, expected ...string) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestSeqTraversalOrders(t *testing.T) {
+	root := createStringTree()
+
+	assertSeqValues(t, slices.Collect(Values(root.PreOrder())),
+		"root", "child1", "grandchild1", "child2", "grandchild2")
+	assertSeqValues(t, slices.Collect(Values(root.PostOrder())),
+		"grandchild1", "child1", "grandchild2", "child2", "root")
+	assertSeqValues(t, slices.Collect(Values(root.BFS())),
+		"root", "child1", "child2", "grandchild1", "grandchild2")
+}
+
+func TestSeqWithDepth(t *testing.T) {
+	root := createStringTree()
+	expected := map[string]int{"root": 0, "child1": 1, "child2": 1, "grandchild1": 2, "grandchild2": 2}
+
+	for name, seq := range map[string]iter.Seq2[int, *Node]{
+		"PreOrder":  root.PreOrderWithDepth(),
+		"PostOrder": root.PostOrderWithDepth(),
+		"BFS":       root.BFSWithDepth(),
+	} {
+		count := 0
+		for depth, node := range seq {
+			count++
+			if expected[node.Value.(string)] != depth {
+				t.Errorf("%s: node %v has depth %d", name, node.Value, depth)
+			}
+		}
+		if count != len(expected) {
+			t.Errorf("%s: expected %d nodes, got %d", name, len(expected), count)
+		}
+	}
+}
+
+func TestSeqEarlyBreak(t *testing.T) {
+	root := createStringTree()
+
+	visited := 0
+	for node := range root.BFS() {
+		visited++
+		if node.Value == "child1" {
+			break
+		}
+	}
+	if visited != 2 {
+		t.Errorf("Expected to stop after 2 nodes, visited %d", visited)
+	}
+
+	visited = 0
+	for range root.PostOrder() {
+		visited++
+		break
+	}
+	if visited != 1 {
+		t.Errorf("Expected to stop after 1 node, visited %d", visited)
+	}
+}
+
+func TestSeqAdapters(t *testing.T) {
+	root := createStringTree()
+
+	leaves := Filter(root.PreOrder(), func(n *Node) bool { return len(n.Children) == 0 })
+	assertSeqValues(t, slices.Collect(Values(leaves)), "grandchild1", "grandchild2")
+
+	lengths := slices.Collect(Map(root.BFS(), func(n *Node) int { return len(n.Value.(string)) }))
+	if !slices.Equal(lengths, []int{4, 6, 6, 11, 11}) {
+		t.Errorf("Unexpected mapped values: %v", lengths)
+	}
+
+	assertSeqValues(t, slices.Collect(Values(Take(root.PreOrder(), 2))), "root", "child1")
+	if got := slices.Collect(Take(root.PreOrder(), 0)); len(got) != 0 {
+		t.Errorf("Take(0) must be empty, got %d", len(got))
+	}
+
+	// Композиция с ранней остановкой
+	pulled := 0
+	counted := Map(root.PreOrder(), func(n *Node) *Node { pulled++; return n })
+	for range Take(counted, 3) {
+	}
+	if pulled != 3 {
+		t.Errorf("Take must stop the underlying traversal, pulled %d", pulled)
+	}
+}
@@ -0,0 +1,91 @@
+package orgtree
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// LessFunc сравнивает два узла при сортировке
+type LessFunc func(a, b *Node) bool
+
+// SortChildren сортирует детей узла. Сортировка стабильная:
+// равные по less узлы сохраняют исходный порядок.
+func (n *Node) SortChildren(less LessFunc) {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		return less(n.Children[i], n.Children[j])
+	})
+	n.invalidateHashes()
+}
+
+// SortRecursive сортирует детей каждого узла поддерева
+func (n *Node) SortRecursive(less LessFunc) {
+	stack := []*Node{n}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node.SortChildren(less)
+		stack = append(stack, node.Children...)
+	}
+}
+
+// ByName сравнивает узлы по имени OrgNode или EmployeeNode
+func ByName(a, b *Node) bool {
+	return valueName(a.Value) < valueName(b.Value)
+}
+
+// BySysName сравнивает узлы по системному имени OrgNode
+func BySysName(a, b *Node) bool {
+	return valueSysName(a.Value) < valueSysName(b.Value)
+}
+
+// ByType сравнивает узлы по системному имени типа
+func ByType(a, b *Node) bool {
+	return typeSysName(a.Value) < typeSysName(b.Value)
+}
+
+// ByID сравнивает узлы по ID. Удобно как последний критерий для полностью детерминированного порядка
+func ByID(a, b *Node) bool {
+	idA, _ := valueID(a.Value)
+	idB, _ := valueID(b.Value)
+	return idA.String() < idB.String()
+}
+
+// ByOrder возвращает компаратор по явному порядковому ключу.
+// Узлы без ключа располагаются после узлов с ключом.
+func ByOrder(order map[uuid.UUID]int) LessFunc {
+	return func(a, b *Node) bool {
+		idA, _ := valueID(a.Value)
+		idB, _ := valueID(b.Value)
+		keyA, okA := order[idA]
+		keyB, okB := order[idB]
+		if okA != okB {
+			return okA
+		}
+		return keyA < keyB
+	}
+}
+
+// ThenBy объединяет компараторы: следующий используется, если предыдущие считают узлы равными
+func ThenBy(less ...LessFunc) LessFunc {
+	return func(a, b *Node) bool {
+		for _, l := range less {
+			if l(a, b) {
+				return true
+			}
+			if l(b, a) {
+				return false
+			}
+		}
+		return false
+	}
+}
+
+// typeSysName возвращает системное имя типа значения или пустую строку
+func typeSysName(value interface{}) string {
+	if t := valueType(value); t != nil {
+		return t.SysName
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package orgtree
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func orgNames(nodes []*Node) []string {
+	names := []string{}
+	for _, node := range nodes {
+		names = append(names, valueName(node.Value))
+	}
+	return names
+}
+
+func assertNames(t *testing.T, got []string, expected ...string) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func createUnsortedTree() *Node {
+	_, teamType, employeeType := createTestNodeTypes()
+	root := NewNode(&OrgNode{ID: uuid.New(), Name: "Root", SysName: "root"})
+	for _, name := range []string{"Gamma", "Alpha", "Beta"} {
+		team := NewNode(&OrgNode{ID: uuid.New(), Name: name, SysName: "team_" + name, Type: teamType})
+		team.AddChild(NewNode(&EmployeeNode{ID: uuid.New(), Name: "Z " + name, Type: employeeType}))
+		team.AddChild(NewNode(&EmployeeNode{ID: uuid.New(), Name: "A " + name, Type: employeeType}))
+		root.AddChild(team)
+	}
+	return root
+}
+
+func TestSortChildren(t *testing.T) {
+	root := createUnsortedTree()
+
+	root.SortChildren(ByName)
+	assertNames(t, orgNames(root.Children), "Alpha", "Beta", "Gamma")
+
+	// Дети детей не сортируются
+	assertNames(t, orgNames(root.Children[0].Children), "Z Alpha", "A Alpha")
+
+	root.SortChildren(func(a, b *Node) bool { return ByName(b, a) })
+	assertNames(t, orgNames(root.Children), "Gamma", "Beta", "Alpha")
+}
+
+func TestSortRecursive(t *testing.T) {
+	root := createUnsortedTree()
+
+	root.SortRecursive(ByName)
+	assertNames(t, orgNames(root.Children), "Alpha", "Beta", "Gamma")
+	for _, team := range root.Children {
+		if valueName(team.Children[0].Value) > valueName(team.Children[1].Value) {
+			t.Errorf("Children of %s are not sorted", valueName(team.Value))
+		}
+	}
+}
+
+func TestComparators(t *testing.T) {
+	departmentType, teamType, _ := createTestNodeTypes()
+	a := NewNode(&OrgNode{ID: uuid.New(), Name: "B", SysName: "a", Type: teamType})
+	b := NewNode(&OrgNode{ID: uuid.New(), Name: "A", SysName: "b", Type: departmentType})
+	c := NewNode(&OrgNode{ID: uuid.New(), Name: "A", SysName: "c", Type: teamType})
+
+	if !BySysName(a, b) || BySysName(b, a) {
+		t.Error("BySysName compares incorrectly")
+	}
+	if !ByType(b, a) || ByType(a, c) || ByType(c, a) {
+		t.Error("ByType compares incorrectly")
+	}
+
+	root := NewNode(nil)
+	root.AddChild(a)
+	root.AddChild(b)
+	root.AddChild(c)
+
+	root.SortChildren(ThenBy(ByType, ByName))
+	if root.Children[0] != b || root.Children[1] != c || root.Children[2] != a {
+		t.Errorf("ThenBy produced wrong order: %v", orgNames(root.Children))
+	}
+
+	order := map[uuid.UUID]int{
+		c.Value.(*OrgNode).ID: 1,
+		a.Value.(*OrgNode).ID: 2,
+	}
+	root.SortChildren(ByOrder(order))
+	if root.Children[0] != c || root.Children[1] != a || root.Children[2] != b {
+		t.Errorf("ByOrder produced wrong order: %v", orgNames(root.Children))
+	}
+}
+
+func TestBuildTreeIsDeterministic(t *testing.T) {
+	build := func() (*TreeBuilder, []*OrgNode) {
+		builder := NewTreeBuilder()
+		roots := []*OrgNode{}
+		for i := 0; i < 20; i++ {
+			node := &OrgNode{ID: uuid.New(), Name: string(rune('A' + i)), SysName: "node"}
+			builder.AddNode(node)
+			roots = append(roots, node)
+		}
+		return builder, roots
+	}
+
+	builder, roots := build()
+	for i := 0; i < 5; i++ {
+		tree := builder.BuildTree()
+		if len(tree.Children) != len(roots) {
+			t.Fatalf("Expected %d roots, got %d", len(roots), len(tree.Children))
+		}
+		for j, child := range tree.Children {
+			if child.Value != roots[j] {
+				t.Fatalf("Root %d is not in insertion order", j)
+			}
+		}
+	}
+
+	// Хеш одинаковых данных не зависит от порядка перебора map
+	if builder.BuildTree().HashString() != builder.BuildTree().HashString() {
+		t.Error("Hash of the same data differs between builds")
+	}
+}
@@ -0,0 +1,183 @@
+package orgtree
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// StatsOptions задает пороги для флагов «здоровья» оргструктуры
+type StatsOptions struct {
+	// MaxSpan — число подчиненных, выше которого охват управления считается слишком широким
+	MaxSpan int
+	// MinChainLength — минимальное число подряд идущих узлов с единственным ребенком,
+	// при котором цепочка попадает в отчет
+	MinChainLength int
+}
+
+// DefaultStatsOptions возвращает пороги по умолчанию
+func DefaultStatsOptions() StatsOptions {
+	return StatsOptions{MaxSpan: 10, MinChainLength: 2}
+}
+
+// SpanStats описывает распределение охвата управления (числа прямых подчиненных)
+type SpanStats struct {
+	Managers int     `json:"managers"`
+	Min      int     `json:"min"`
+	Max      int     `json:"max"`
+	Avg      float64 `json:"avg"`
+	Median   float64 `json:"median"`
+}
+
+// TreeStats содержит статистику дерева
+type TreeStats struct {
+	Nodes    int     `json:"nodes"`
+	Leaves   int     `json:"leaves"`
+	MaxDepth int     `json:"max_depth"`
+	AvgDepth float64 `json:"avg_depth"`
+	// DepthHistogram[d] — число узлов на глубине d
+	DepthHistogram []int `json:"depth_histogram"`
+
+	// Span — охват управления по всем узлам, у которых есть дети
+	Span SpanStats `json:"span"`
+	// SpanByManager — число прямых подчиненных для узлов с ID
+	SpanByManager map[uuid.UUID]int `json:"span_by_manager"`
+
+	// TypeCounts — число узлов по NodeType.SysName
+	TypeCounts map[string]int `json:"type_counts"`
+	// EmployeesPerDepartment — число сотрудников (EmployeeNode), непосредственно входящих в OrgNode
+	EmployeesPerDepartment map[uuid.UUID]int `json:"employees_per_department"`
+
+	// SingleChildChains — цепочки узлов, у каждого из которых ровно один ребенок
+	SingleChildChains [][]*Node `json:"-"`
+	// WideSpans — узлы, у которых больше StatsOptions.MaxSpan подчиненных
+	WideSpans []*Node `json:"-"`
+}
+
+// Stats собирает статистику дерева с порогами по умолчанию
+func Stats(root *Node) *TreeStats {
+	return StatsWithOptions(root, DefaultStatsOptions())
+}
+
+// StatsWithOptions собирает статистику дерева.
+// Корень с nil-значением (заглушка TreeBuilder) не учитывается, его дети имеют глубину 0.
+// Для nil возвращается пустая статистика.
+func StatsWithOptions(root *Node, opts StatsOptions) *TreeStats {
+	stats := &TreeStats{
+		DepthHistogram:         []int{},
+		SpanByManager:          make(map[uuid.UUID]int),
+		TypeCounts:             make(map[string]int),
+		EmployeesPerDepartment: make(map[uuid.UUID]int),
+	}
+	if root == nil {
+		return stats
+	}
+
+	type entry struct {
+		node    *Node
+		depth   int
+		inChain bool
+	}
+
+	stack := []entry{}
+	if root.Value == nil {
+		for i := len(root.Children) - 1; i >= 0; i-- {
+			stack = append(stack, entry{node: root.Children[i]})
+		}
+	} else {
+		stack = append(stack, entry{node: root})
+	}
+
+	spans := []int{}
+	depthSum := 0
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := e.node
+
+		stats.Nodes++
+		depthSum += e.depth
+		if e.depth > stats.MaxDepth {
+			stats.MaxDepth = e.depth
+		}
+		for len(stats.DepthHistogram) <= e.depth {
+			stats.DepthHistogram = append(stats.DepthHistogram, 0)
+		}
+		stats.DepthHistogram[e.depth]++
+
+		if t := valueType(node.Value); t != nil {
+			stats.TypeCounts[t.SysName]++
+		}
+
+		span := len(node.Children)
+		if span == 0 {
+			stats.Leaves++
+		} else {
+			spans = append(spans, span)
+			if id, ok := valueID(node.Value); ok {
+				stats.SpanByManager[id] = span
+			}
+			if opts.MaxSpan > 0 && span > opts.MaxSpan {
+				stats.WideSpans = append(stats.WideSpans, node)
+			}
+		}
+
+		if orgNode, ok := node.Value.(*OrgNode); ok && orgNode != nil {
+			employees := 0
+			for _, child := range node.Children {
+				if _, ok := child.Value.(*EmployeeNode); ok {
+					employees++
+				}
+			}
+			stats.EmployeesPerDepartment[orgNode.ID] = employees
+		}
+
+		// Цепочка начинается с узла с единственным ребенком, родитель которого в цепочку не входит
+		if span == 1 && !e.inChain {
+			chain := []*Node{}
+			for current := node; len(current.Children) == 1; current = current.Children[0] {
+				chain = append(chain, current)
+			}
+			if len(chain) >= opts.MinChainLength {
+				stats.SingleChildChains = append(stats.SingleChildChains, chain)
+			}
+		}
+
+		for i := len(node.Children) - 1; i >= 0; i-- {
+			stack = append(stack, entry{node: node.Children[i], depth: e.depth + 1, inChain: span == 1})
+		}
+	}
+
+	if stats.Nodes > 0 {
+		stats.AvgDepth = float64(depthSum) / float64(stats.Nodes)
+	}
+	stats.Span = spanStats(spans)
+	return stats
+}
+
+// spanStats вычисляет минимум, максимум, среднее и медиану охвата управления
+func spanStats(spans []int) SpanStats {
+	if len(spans) == 0 {
+		return SpanStats{}
+	}
+	sort.Ints(spans)
+
+	sum := 0
+	for _, span := range spans {
+		sum += span
+	}
+
+	result := SpanStats{
+		Managers: len(spans),
+		Min:      spans[0],
+		Max:      spans[len(spans)-1],
+		Avg:      float64(sum) / float64(len(spans)),
+	}
+	mid := len(spans) / 2
+	if len(spans)%2 == 1 {
+		result.Median = float64(spans[mid])
+	} else {
+		result.Median = float64(spans[mid-1]+spans[mid]) / 2
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package orgtree
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestStats(t *testing.T) {
+	root, ids := createIndexedTree()
+	stats := Stats(root)
+
+	if stats.Nodes != 5 || stats.Leaves != 3 {
+		t.Errorf("Expected 5 nodes and 3 leaves, got %d and %d", stats.Nodes, stats.Leaves)
+	}
+	if stats.MaxDepth != 2 || stats.AvgDepth != 0.8 {
+		t.Errorf("Expected max depth 2 and avg 0.8, got %d and %v", stats.MaxDepth, stats.AvgDepth)
+	}
+	expectedHistogram := []int{2, 2, 1}
+	if len(stats.DepthHistogram) != len(expectedHistogram) {
+		t.Fatalf("Expected histogram %v, got %v", expectedHistogram, stats.DepthHistogram)
+	}
+	for i, count := range expectedHistogram {
+		if stats.DepthHistogram[i] != count {
+			t.Errorf("Expected histogram %v, got %v", expectedHistogram, stats.DepthHistogram)
+		}
+	}
+
+	expectedSpan := SpanStats{Managers: 2, Min: 1, Max: 2, Avg: 1.5, Median: 1.5}
+	if stats.Span != expectedSpan {
+		t.Errorf("Expected span %+v, got %+v", expectedSpan, stats.Span)
+	}
+	if stats.SpanByManager[ids["it_department"]] != 2 || stats.SpanByManager[ids["dev_team"]] != 1 {
+		t.Errorf("Unexpected spans by manager: %v", stats.SpanByManager)
+	}
+
+	if stats.TypeCounts["department"] != 2 || stats.TypeCounts["team"] != 2 || stats.TypeCounts["employee"] != 1 {
+		t.Errorf("Unexpected type counts: %v", stats.TypeCounts)
+	}
+	if stats.EmployeesPerDepartment[ids["dev_team"]] != 1 || stats.EmployeesPerDepartment[ids["it_department"]] != 0 {
+		t.Errorf("Unexpected employees per department: %v", stats.EmployeesPerDepartment)
+	}
+	if len(stats.EmployeesPerDepartment) != 4 {
+		t.Errorf("Expected 4 departments, got %d", len(stats.EmployeesPerDepartment))
+	}
+
+	if len(stats.SingleChildChains) != 0 || len(stats.WideSpans) != 0 {
+		t.Error("Expected no health flags for a small tree")
+	}
+}
+
+func TestStatsHealthFlags(t *testing.T) {
+	root := NewNode(&OrgNode{ID: uuid.New(), Name: "Root"})
+	a := NewNode(&OrgNode{ID: uuid.New(), Name: "A"})
+	b := NewNode(&OrgNode{ID: uuid.New(), Name: "B"})
+	c := NewNode(&OrgNode{ID: uuid.New(), Name: "C"})
+	root.AddChild(a)
+	a.AddChild(b)
+	b.AddChild(c)
+	for i := 0; i < 4; i++ {
+		c.AddChild(NewNode(&EmployeeNode{ID: uuid.New()}))
+	}
+
+	stats := StatsWithOptions(root, StatsOptions{MaxSpan: 3, MinChainLength: 2})
+
+	if len(stats.SingleChildChains) != 1 {
+		t.Fatalf("Expected 1 chain, got %d", len(stats.SingleChildChains))
+	}
+	chain := stats.SingleChildChains[0]
+	if len(chain) != 3 || chain[0] != root || chain[2] != b {
+		t.Errorf("Unexpected chain: %v", orgNames(chain))
+	}
+
+	if len(stats.WideSpans) != 1 || stats.WideSpans[0] != c {
+		t.Errorf("Expected C to have a wide span, got %v", orgNames(stats.WideSpans))
+	}
+	if stats.Span.Median != 1 || stats.Span.Max != 4 {
+		t.Errorf("Unexpected span stats: %+v", stats.Span)
+	}
+
+	if len(StatsWithOptions(root, StatsOptions{MinChainLength: 4}).SingleChildChains) != 0 {
+		t.Error("Chain shorter than MinChainLength must not be reported")
+	}
+}
+
+func TestStatsEmptyWrapper(t *testing.T) {
+	stats := Stats(NewTreeBuilder().BuildTree())
+	if stats.Nodes != 0 || stats.Span.Managers != 0 || len(stats.DepthHistogram) != 0 {
+		t.Errorf("Expected empty stats, got %+v", stats)
+	}
+
+	stats = Stats(nil)
+	if stats.Nodes != 0 || stats.TypeCounts == nil || len(stats.DepthHistogram) != 0 {
+		t.Errorf("Expected empty stats for nil root, got %+v", stats)
+	}
+}
@@ -1,6 +1,9 @@
 package orgtree
 
 import (
+	"bytes"
+	"sort"
+
 	"github.com/google/uuid"
 )
 
@@ -9,6 +12,8 @@ type TreeBuilder struct {
 	nodes         map[uuid.UUID]*OrgNode
 	edges         []*Edge
 	employeeNodes map[uuid.UUID]*EmployeeNode
+	// order хранит ID узлов в порядке добавления, чтобы BuildTree не зависел от обхода map
+	order []uuid.UUID
 }
 
 // NewTreeBuilder создает новый экземпляр TreeBuilder
@@ -24,18 +29,75 @@ func NewTreeBuilder() *TreeBuilder {
 func (tb *TreeBuilder) AddNode(node interface{}) {
 	switch node := node.(type) {
 	case *OrgNode:
+		tb.remember(node.ID)
 		tb.nodes[node.ID] = node
 	case *EmployeeNode:
+		tb.remember(node.ID)
 		tb.employeeNodes[node.ID] = node
 	}
 }
 
+// remember запоминает порядок добавления узла. ID, удаленные из карт напрямую,
+// вычищаются из order, когда их становится больше, чем живых узлов
+func (tb *TreeBuilder) remember(id uuid.UUID) {
+	if _, ok := tb.nodes[id]; ok {
+		return
+	}
+	if _, ok := tb.employeeNodes[id]; ok {
+		return
+	}
+	if len(tb.order) > 2*(len(tb.nodes)+len(tb.employeeNodes)) {
+		tb.order = tb.knownOrder()
+	}
+	tb.order = append(tb.order, id)
+}
+
+// knownOrder возвращает ID из order, которые еще есть в картах, без повторов
+func (tb *TreeBuilder) knownOrder() []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(tb.order))
+	order := make([]uuid.UUID, 0, len(tb.order))
+	for _, id := range tb.order {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, ok := tb.value(id); ok {
+			order = append(order, id)
+		}
+	}
+	return order
+}
+
+// orderedIDs возвращает ID всех узлов: сначала в порядке добавления, затем записанные
+// напрямую в карту из Nodes() в порядке возрастания ID. Построитель не изменяется
+func (tb *TreeBuilder) orderedIDs() []uuid.UUID {
+	order := tb.knownOrder()
+	known := make(map[uuid.UUID]bool, len(order))
+	for _, id := range order {
+		known[id] = true
+	}
+	var missing []uuid.UUID
+	for id := range tb.nodes {
+		if !known[id] {
+			missing = append(missing, id)
+		}
+	}
+	for id := range tb.employeeNodes {
+		if !known[id] {
+			missing = append(missing, id)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool { return bytes.Compare(missing[i][:], missing[j][:]) < 0 })
+	return append(order, missing...)
+}
+
 // AddEdge добавляет связь в построитель
 func (tb *TreeBuilder) AddEdge(edge *Edge) {
 	tb.edges = append(tb.edges, edge)
 }
 
-// Nodes возвращает карту добавленных узлов
+// Nodes возвращает карту добавленных узлов. Узлы, записанные в нее напрямую,
+// попадают в дерево после узлов, добавленных через AddNode
 func (tb *TreeBuilder) Nodes() map[uuid.UUID]*OrgNode {
 	return tb.nodes
 }
@@ -51,16 +113,19 @@ func (tb *TreeBuilder) Node(id uuid.UUID) (*OrgNode, bool) {
 	return node, ok
 }
 
-// BuildTree строит дерево из добавленных данных
+// BuildTree строит дерево из добавленных данных.
+// Корневые узлы следуют в порядке добавления узлов, дети — в порядке добавления связей.
 func (tb *TreeBuilder) BuildTree() *Node {
+	order := tb.orderedIDs()
+
 	// Создаем все узлы дерева
 	treeNodes := make(map[uuid.UUID]*Node)
-	for _, orgNode := range tb.nodes {
-		treeNodes[orgNode.ID] = NewNode(orgNode)
-	}
-
-	for _, employeeNode := range tb.employeeNodes {
-		treeNodes[employeeNode.ID] = NewNode(employeeNode)
+	for _, id := range order {
+		if employeeNode, ok := tb.employeeNodes[id]; ok {
+			treeNodes[id] = NewNode(employeeNode)
+		} else if orgNode, ok := tb.nodes[id]; ok {
+			treeNodes[id] = NewNode(orgNode)
+		}
 	}
 
 	// Ищем входящие связи
@@ -71,8 +136,8 @@ func (tb *TreeBuilder) BuildTree() *Node {
 
 	// Определяем корневые узлы
 	rootNodes := []*Node{}
-	for id, node := range treeNodes {
-		if !hasIncoming[id] {
+	for _, id := range order {
+		if node, ok := treeNodes[id]; ok && !hasIncoming[id] {
 			rootNodes = append(rootNodes, node)
 		}
 	}
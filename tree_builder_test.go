@@ -1,6 +1,7 @@
 package orgtree
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/google/uuid"
@@ -784,3 +785,67 @@ func TestTreeBuilderFilterEmployees(t *testing.T) {
 		t.Errorf("Ожидалось %d сотрудников, найдено %d", len(employees), employeeCount)
 	}
 }
+
+func TestTreeBuilderNodesWrittenDirectly(t *testing.T) {
+	builder := NewTreeBuilder()
+	office := &OrgNode{ID: uuid.New(), Name: "Главный офис", SysName: "main_office"}
+	builder.AddNode(office)
+
+	// Узлы, записанные в карту из Nodes(), попадают в дерево, как и раньше
+	it := &OrgNode{ID: uuid.New(), Name: "IT отдел", SysName: "it_department"}
+	hr := &OrgNode{ID: uuid.New(), Name: "HR отдел", SysName: "hr_department"}
+	builder.Nodes()[it.ID] = it
+	builder.Nodes()[hr.ID] = hr
+	builder.AddEdge(&Edge{FromNode: office.ID, ToNode: it.ID})
+
+	tree := builder.BuildTree()
+	if len(tree.Children) != 2 || tree.Children[0].Value != office || tree.Children[1].Value != hr {
+		t.Fatalf("Expected office and HR as roots, got %v", orgNames(tree.Children))
+	}
+	if len(tree.Children[0].Children) != 1 || tree.Children[0].Children[0].Value != it {
+		t.Error("Directly written node must get its edges")
+	}
+
+	// Порядок не зависит от обхода карты
+	again := builder.BuildTree()
+	if again.Children[1].Value != hr {
+		t.Error("Order of directly written nodes must be stable")
+	}
+	if errs := builder.Validate(); len(errs) != 0 {
+		t.Errorf("Unexpected validation errors: %v", errs)
+	}
+
+	// Удаленный из карты и добавленный заново узел не дублируется
+	delete(builder.Nodes(), hr.ID)
+	builder.AddNode(hr)
+	if tree := builder.BuildTree(); len(tree.Children) != 2 || tree.Children[1].Value != hr {
+		t.Errorf("Expected office and HR once, got %v", orgNames(tree.Children))
+	}
+}
+
+func TestTreeBuilderBuildTreeConcurrent(t *testing.T) {
+	builder := NewTreeBuilder()
+	office := &OrgNode{ID: uuid.New(), Name: "Главный офис", SysName: "main_office"}
+	it := &OrgNode{ID: uuid.New(), Name: "IT отдел", SysName: "it_department"}
+	builder.AddNode(office)
+	builder.Nodes()[it.ID] = it
+	builder.AddEdge(&Edge{FromNode: office.ID, ToNode: it.ID})
+	order := len(builder.order)
+
+	// BuildTree и Validate только читают построитель
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if tree := builder.BuildTree(); len(tree.Children) != 1 || len(tree.Children[0].Children) != 1 {
+				t.Error("Unexpected tree shape")
+			}
+			builder.Validate()
+		}()
+	}
+	wg.Wait()
+	if len(builder.order) != order {
+		t.Error("Read paths must not change the builder")
+	}
+}
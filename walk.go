@@ -0,0 +1,61 @@
+package orgtree
+
+import "errors"
+
+var (
+	// SkipSubtree возвращается из функции обхода, чтобы не заходить в потомков текущего узла
+	SkipSubtree = errors.New("orgtree: пропустить поддерево")
+	// SkipAll возвращается из функции обхода, чтобы завершить обход без ошибки
+	SkipAll = errors.New("orgtree: завершить обход")
+)
+
+// WalkContext описывает положение узла при обходе
+type WalkContext struct {
+	// Depth — глубина узла относительно корня обхода
+	Depth int
+	// Parent — родитель узла или nil для корня обхода
+	Parent *Node
+	// Index — номер узла среди детей родителя (0 для корня обхода)
+	Index int
+	// Path — путь от корня обхода до узла включительно.
+	// Срез переиспользуется, его нужно скопировать, чтобы сохранить после возврата из функции.
+	Path []*Node
+}
+
+// WalkFunc вызывается для каждого узла при обходе Walk
+type WalkFunc func(n *Node, ctx WalkContext) error
+
+// Walk обходит дерево в прямом порядке по аналогии с filepath.WalkDir.
+// Если fn возвращает SkipSubtree, потомки узла пропускаются; SkipAll завершает обход
+// и Walk возвращает nil; любая другая ошибка завершает обход и возвращается из Walk.
+func Walk(root *Node, fn WalkFunc) error {
+	type entry struct {
+		node   *Node
+		parent *Node
+		depth  int
+		index  int
+	}
+
+	path := []*Node{}
+	stack := []entry{{node: root}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		path = append(path[:e.depth], e.node)
+		err := fn(e.node, WalkContext{Depth: e.depth, Parent: e.parent, Index: e.index, Path: path})
+		switch {
+		case err == SkipSubtree:
+			continue
+		case err == SkipAll:
+			return nil
+		case err != nil:
+			return err
+		}
+
+		for i := len(e.node.Children) - 1; i >= 0; i-- {
+			stack = append(stack, entry{node: e.node.Children[i], parent: e.node, depth: e.depth + 1, index: i})
+		}
+	}
+	return nil
+}
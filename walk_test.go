@@ -0,0 +1,160 @@
+package orgtree
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWalkContext(t *testing.T) {
+	root := createStringTree()
+
+	type visit struct {
+		depth  int
+		parent interface{}
+		index  int
+		path   []interface{}
+	}
+	visits := map[string]visit{}
+	err := Walk(root, func(n *Node, ctx WalkContext) error {
+		var parent interface{}
+		if ctx.Parent != nil {
+			parent = ctx.Parent.Value
+		}
+		path := []interface{}{}
+		for _, p := range ctx.Path {
+			path = append(path, p.Value)
+		}
+		visits[n.Value.(string)] = visit{ctx.Depth, parent, ctx.Index, path}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	if len(visits) != 5 {
+		t.Fatalf("Expected 5 visits, got %d", len(visits))
+	}
+	gc := visits["grandchild2"]
+	if gc.depth != 2 || gc.parent != "child2" || gc.index != 0 {
+		t.Errorf("Unexpected context for grandchild2: %+v", gc)
+	}
+	assertSeqValues(t, gc.path, "root", "child2", "grandchild2")
+	if c := visits["child2"]; c.index != 1 || c.parent != "root" {
+		t.Errorf("Unexpected context for child2: %+v", c)
+	}
+	if r := visits["root"]; r.parent != nil || r.depth != 0 {
+		t.Errorf("Unexpected context for root: %+v", r)
+	}
+}
+
+func TestWalkSkipControls(t *testing.T) {
+	root := createStringTree()
+
+	visited := []interface{}{}
+	err := Walk(root, func(n *Node, ctx WalkContext) error {
+		visited = append(visited, n.Value)
+		if n.Value == "child1" {
+			return SkipSubtree
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	assertSeqValues(t, visited, "root", "child1", "child2", "grandchild2")
+
+	visited = visited[:0]
+	err = Walk(root, func(n *Node, ctx WalkContext) error {
+		visited = append(visited, n.Value)
+		if n.Value == "grandchild1" {
+			return SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SkipAll must not be returned as error: %v", err)
+	}
+	assertSeqValues(t, visited, "root", "child1", "grandchild1")
+
+	stopErr := errors.New("stop")
+	err = Walk(root, func(n *Node, ctx WalkContext) error {
+		if n.Value == "child2" {
+			return stopErr
+		}
+		return nil
+	})
+	if err != stopErr {
+		t.Errorf("Expected custom error, got %v", err)
+	}
+}
+
+func TestIteratorControls(t *testing.T) {
+	root := createStringTree()
+
+	t.Run("PreOrder", func(t *testing.T) {
+		it := NewPreOrderIterator(root)
+		visited := []interface{}{}
+		for node := it.Next(); node != nil; node = it.Next() {
+			visited = append(visited, node.Value)
+			if node.Value == "grandchild2" {
+				if it.Depth() != 2 || len(it.Path()) != 3 || it.Path()[1].Value != "child2" {
+					t.Errorf("Unexpected depth %d or path for grandchild2", it.Depth())
+				}
+			}
+			if node.Value == "child1" {
+				it.SkipSubtree()
+			}
+		}
+		assertSeqValues(t, visited, "root", "child1", "child2", "grandchild2")
+
+		it = NewPreOrderIterator(root)
+		it.Next()
+		it.Stop()
+		if it.Next() != nil {
+			t.Error("Expected nil after Stop")
+		}
+	})
+
+	t.Run("BFS", func(t *testing.T) {
+		it := NewBFSIterator(root)
+		visited := []interface{}{}
+		depths := []int{}
+		for node := it.Next(); node != nil; node = it.Next() {
+			visited = append(visited, node.Value)
+			depths = append(depths, it.Depth())
+			if node.Value == "child2" {
+				it.SkipSubtree()
+			}
+		}
+		assertSeqValues(t, visited, "root", "child1", "child2", "grandchild1")
+		if depths[0] != 0 || depths[2] != 1 || depths[3] != 2 {
+			t.Errorf("Unexpected depths: %v", depths)
+		}
+
+		it = NewBFSIterator(root)
+		it.Next()
+		it.Stop()
+		if it.Next() != nil {
+			t.Error("Expected nil after Stop")
+		}
+	})
+
+	t.Run("PostOrder", func(t *testing.T) {
+		it := NewPostOrderIterator(root)
+		node := it.Next()
+		if node.Value != "grandchild1" || it.Depth() != 2 {
+			t.Errorf("Unexpected first node %v at depth %d", node.Value, it.Depth())
+		}
+		if path := it.Path(); len(path) != 3 || path[0] != root || path[2] != node {
+			t.Errorf("Unexpected path: %v", path)
+		}
+		it.Next()
+		if it.Depth() != 1 {
+			t.Errorf("Expected depth 1 for child1, got %d", it.Depth())
+		}
+		it.Stop()
+		if it.Next() != nil {
+			t.Error("Expected nil after Stop")
+		}
+	})
+}